@@ -0,0 +1,51 @@
+package git2graph
+
+const boundariesKey = "boundaries" // Paths to the parents missing from the input
+
+// Boundary marks a path going to a parent that is not in the input (shallow clone, truncated history),
+// so that renderers can draw where the history continues. The path ends below the last row, in its lane.
+type Boundary struct {
+	ID   string `json:"id"`   // Id of the missing parent
+	Lane int    `json:"lane"` // Column of the path where it exits the graph
+}
+
+// Return the boundaries of the paths of nodes going to parents missing from inputNodes, by child.
+// Missing parents are below the last node, only the parents of nodes that are there are looked up in inputNodes.
+func findBoundaries(inputNodes []*Node, nodes []*internalNode) map[*internalNode][]*Boundary {
+	if len(nodes) == 0 {
+		return nil
+	}
+	lastIdx := *nodes[len(nodes)-1].idx
+	candidates := make(map[string]bool)
+	for _, node := range nodes {
+		for _, parent := range node.parents {
+			if *parent.idx > lastIdx {
+				candidates[parent.id] = true
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	for _, node := range inputNodes {
+		delete(candidates, node.GetID())
+	}
+	boundaries := make(map[*internalNode][]*Boundary)
+	for _, node := range nodes {
+		for _, parent := range node.parents {
+			if path := node.parentsPaths[parent.id]; candidates[parent.id] && path.isValid() {
+				boundaries[node] = append(boundaries[node], &Boundary{ID: parent.id, Lane: path.last().getX()})
+			}
+		}
+	}
+	return boundaries
+}
+
+// Set the boundaries property of an output node, or remove it if it has none
+func setBoundaries(node *Node, boundaries []*Boundary) {
+	if len(boundaries) == 0 {
+		delete(*node, boundariesKey)
+		return
+	}
+	(*node)[boundariesKey] = boundaries
+}
@@ -0,0 +1,56 @@
+package git2graph
+
+import (
+	"context"
+	"testing"
+)
+
+// Shallow history: the parents x, y and z are not in the input
+func boundaryTestNodes() []*Node {
+	return []*Node{
+		{idKey: "a", parentsKey: []string{"b", "x"}},
+		{idKey: "b", parentsKey: []string{"y"}},
+		{idKey: "c", parentsKey: []string{"z"}},
+	}
+}
+
+func assertBoundaries(t *testing.T, node *Node, expected ...Boundary) {
+	t.Helper()
+	boundaries, _ := (*node)[boundariesKey].([]*Boundary)
+	assertEq(t, len(expected), len(boundaries))
+	for i := range boundaries {
+		assertEq(t, expected[i], *boundaries[i])
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	for _, layout := range []func(context.Context, []*Node, *Options) (*Out, error){LayoutContext, LayoutRowsContext} {
+		out, err := layout(context.Background(), boundaryTestNodes(), nil)
+		assertEq(t, nil, err)
+		assertBoundaries(t, out.Nodes[0], Boundary{ID: "x", Lane: 1})
+		assertBoundaries(t, out.Nodes[1], Boundary{ID: "y", Lane: 0})
+		assertBoundaries(t, out.Nodes[2], Boundary{ID: "z", Lane: 2})
+	}
+}
+
+// Parents below the page are not boundaries, parents missing from the input are even past the page
+func TestBoundariesPage(t *testing.T) {
+	inputNodes := boundaryTestNodes()
+	out, err := LayoutContext(context.Background(), inputNodes, &Options{Limit: 1})
+	assertEq(t, nil, err)
+	assertEq(t, 1, len(out.Nodes))
+	assertBoundaries(t, out.Nodes[0], Boundary{ID: "x", Lane: 1})
+
+	// The property of a previous layout is removed
+	(*inputNodes[1])[parentsKey] = []string{"c"}
+	out, err = LayoutContext(context.Background(), inputNodes, nil)
+	assertEq(t, nil, err)
+	assertBoundaries(t, out.Nodes[1])
+}
+
+func TestBoundariesCollapsed(t *testing.T) {
+	out, err := LayoutContext(context.Background(), boundaryTestNodes(), &Options{MaxLanes: 2})
+	assertEq(t, nil, err)
+	assertBoundaries(t, out.Nodes[2], Boundary{ID: "z", Lane: 1})
+	assertEq(t, 0, len(Check(out)))
+}
@@ -0,0 +1,333 @@
+package git2graph
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultCacheInterval is the default number of rows in between two lane-state snapshots
+const DefaultCacheInterval = 1000
+
+const layoutCacheVersion = 5
+
+// LayoutCache persists lane-state snapshots taken every `interval` rows while laying out a graph.
+// Each snapshot is validated against the ids (and parents) of all the rows above it and of its own row,
+// which the layout of the row above looks ahead at,
+// so that a deep page can resume the layout from the nearest snapshot instead of row 0.
+type LayoutCache struct {
+	path      string
+	interval  int
+	snapshots map[int]*layoutSnapshot
+	dirty     bool
+}
+
+type layoutCacheFile struct {
+	Version   int               `json:"version"`
+	Interval  int               `json:"interval"`
+	Snapshots []*layoutSnapshot `json:"snapshots"`
+}
+
+// Everything needed to resume setColumns right before processing row `Row`.
+type layoutSnapshot struct {
+	Row    int             `json:"row"`
+	Hash   string          `json:"hash"`
+	TmpRow int             `json:"tmpRow"`
+	Column int             `json:"column"`
+	Colors []snapshotColor `json:"colors"`
+	Open   []*snapshotNode `json:"open"`   // Nodes referenced as parent but not processed yet, in followingNodes order
+	Closed []*snapshotNode `json:"closed"` // Already processed children of the open nodes
+	Stubs  []*snapshotStub `json:"stubs"`  // Already processed parents of the closed nodes
+}
+
+type snapshotColor struct {
+	ReleaseIdx int  `json:"releaseIdx"`
+	InUse      bool `json:"inUse"`
+}
+
+type snapshotStub struct {
+	ID  string `json:"id"`
+	Idx int    `json:"idx"`
+}
+
+// A reference to a node of the snapshot. Kind: 0 open, 1 closed, 2 stub
+type snapshotRef [2]int
+
+const (
+	refOpen = iota
+	refClosed
+	refStub
+)
+
+type snapshotNode struct {
+	ID        string            `json:"id"`
+	Idx       int               `json:"idx"`
+	Column    int               `json:"column"`
+	ColorIdx  int               `json:"colorIdx"`
+	LaneTip   string            `json:"laneTip"`
+	Parents   []snapshotRef     `json:"parents,omitempty"`
+	Children  []int             `json:"children,omitempty"` // Indices in Closed
+	Paths     [][]snapshotPoint `json:"paths,omitempty"`    // Aligned with Parents
+	ColorsIdx []int             `json:"pathsColors,omitempty"`
+	LaneTips  []string          `json:"pathsLaneTips,omitempty"`
+	MovedBack []bool            `json:"pathsMovedBack,omitempty"`
+	NewLane   []bool            `json:"pathsNewLane,omitempty"`
+}
+
+// x, y, type, and the index of the open node whose row the point follows (-1 if the row is final)
+type snapshotPoint [4]int
+
+// NewLayoutCache opens (or creates) a layout cache stored at path.
+// A cache file written with a different interval is discarded.
+func NewLayoutCache(path string, interval int) (*LayoutCache, error) {
+	if interval <= 0 {
+		interval = DefaultCacheInterval
+	}
+	c := &LayoutCache{path: path, interval: interval, snapshots: make(map[int]*layoutSnapshot)}
+	by, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var f layoutCacheFile
+	if err := json.Unmarshal(by, &f); err != nil || f.Version != layoutCacheVersion || f.Interval != interval {
+		return c, nil
+	}
+	for _, s := range f.Snapshots {
+		c.snapshots[s.Row] = s
+	}
+	return c, nil
+}
+
+// RepoCachePath returns the default location of the layout cache for the repository in dir
+func RepoCachePath(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd.Dir = dir
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	gitDir := strings.TrimSpace(string(outBytes))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return filepath.Join(gitDir, "git2graph", "layout.json"), nil
+}
+
+// Save writes the cache to disk if new snapshots were recorded
+func (c *LayoutCache) Save() error {
+	if c == nil || !c.dirty || c.path == "" {
+		return nil
+	}
+	f := layoutCacheFile{Version: layoutCacheVersion, Interval: c.interval}
+	for _, s := range c.snapshots {
+		f.Snapshots = append(f.Snapshots, s)
+	}
+	slices.SortFunc(f.Snapshots, func(a, b *layoutSnapshot) int { return a.Row - b.Row })
+	by, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, by, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// Running hash of the ids and parents of the rows, used to validate snapshots
+type rowsHasher struct {
+	h hash.Hash
+}
+
+func newRowsHasher() *rowsHasher {
+	return &rowsHasher{h: sha256.New()}
+}
+
+func (r *rowsHasher) add(n *Node) {
+	r.h.Write([]byte(n.GetID()))
+	for _, parentID := range n.GetParents() {
+		r.h.Write([]byte{0})
+		r.h.Write([]byte(parentID))
+	}
+	r.h.Write([]byte{'\n'})
+}
+
+func (r *rowsHasher) sum() string {
+	return hex.EncodeToString(r.h.Sum(nil))
+}
+
+// Find the deepest valid snapshot taken at or before the `from` node
+func (c *LayoutCache) nearest(inputNodes []*Node, from string) *layoutSnapshot {
+	if c == nil || from == "" {
+		return nil
+	}
+	var best *layoutSnapshot
+	hasher := newRowsHasher()
+	for idx, rawNode := range inputNodes {
+		hasher.add(rawNode)
+		if idx > 0 && idx%c.interval == 0 {
+			if s, ok := c.snapshots[idx]; ok && s.Hash == hasher.sum() {
+				best = s
+			}
+		}
+		if rawNode.GetID() == from {
+			return best
+		}
+	}
+	return nil
+}
+
+// Return either or not a snapshot must be taken at row idx, and its hash, hasher having the rows up to idx included.
+// A snapshot taken on a history that changed since is replaced.
+func (c *LayoutCache) shouldRecord(idx int, hasher *rowsHasher) (string, bool) {
+	if c == nil || idx == 0 || idx%c.interval != 0 {
+		return "", false
+	}
+	hash := hasher.sum()
+	s, ok := c.snapshots[idx]
+	return hash, !ok || s.Hash != hash
+}
+
+func (c *LayoutCache) record(s *layoutSnapshot) {
+	c.snapshots[s.Row] = s
+	c.dirty = true
+}
+
+func takeSnapshot(row int, hash string, tmpRow int, followingNodes *internalNodeSet, columnMan *columnManager, colorsMan *colorsManager) *layoutSnapshot {
+	s := &layoutSnapshot{Row: row, Hash: hash, TmpRow: tmpRow, Column: columnMan.c}
+	for _, clr := range colorsMan.colors {
+		s.Colors = append(s.Colors, snapshotColor{ReleaseIdx: clr.releaseIdx, InUse: clr.inUse})
+	}
+	refs := make(map[*internalNode]snapshotRef)
+	openRows := make(map[*int]int)
+	following := followingNodes.All()
+	for i, n := range following {
+		refs[n] = snapshotRef{refOpen, i}
+		openRows[n.idx] = i
+		s.Open = append(s.Open, &snapshotNode{ID: n.id, Idx: *n.idx, Column: n.column, ColorIdx: n.colorIdx, LaneTip: n.laneTip})
+	}
+	refOf := func(n *internalNode) snapshotRef {
+		if ref, ok := refs[n]; ok {
+			return ref
+		}
+		ref := snapshotRef{refStub, len(s.Stubs)}
+		refs[n] = ref
+		s.Stubs = append(s.Stubs, &snapshotStub{ID: n.id, Idx: *n.idx})
+		return ref
+	}
+	var closed []*internalNode
+	for i, n := range following {
+		for _, child := range n.children {
+			if _, ok := refs[child]; !ok {
+				refs[child] = snapshotRef{refClosed, len(s.Closed)}
+				closed = append(closed, child)
+				s.Closed = append(s.Closed, &snapshotNode{ID: child.id, Idx: *child.idx, Column: child.column,
+					ColorIdx: child.colorIdx, LaneTip: child.laneTip})
+			}
+			s.Open[i].Children = append(s.Open[i].Children, refs[child][1])
+		}
+	}
+	for i, n := range s.Closed {
+		child := closed[i]
+		for _, parent := range child.parents {
+			n.Parents = append(n.Parents, refOf(parent))
+			path := child.parentsPaths[parent.id]
+			points := make([]snapshotPoint, len(path.Points))
+			for j, p := range path.Points {
+				openIdx := -1
+				if pt, ok := p.(*Point); ok {
+					if o, ok := openRows[pt.y]; ok {
+						openIdx = o
+					}
+				}
+				points[j] = snapshotPoint{p.getX(), p.GetY(), int(p.getType()), openIdx}
+			}
+			n.Paths = append(n.Paths, points)
+			n.ColorsIdx = append(n.ColorsIdx, path.colorIdx)
+			n.LaneTips = append(n.LaneTips, path.laneTip)
+			n.MovedBack = append(n.MovedBack, path.movedBack)
+			n.NewLane = append(n.NewLane, path.newLane)
+		}
+	}
+	return s
+}
+
+// Rebuild the setColumns state saved in the snapshot
+func (s *layoutSnapshot) restore() (tmpRow int, unassignedNodes map[string]*internalNode, followingNodes *internalNodeSet,
+	columnMan *columnManager, colorsMan *colorsManager) {
+	colorsMan = newColorsManager()
+	for _, clr := range s.Colors {
+		colorsMan.colors = append(colorsMan.colors, &color{releaseIdx: clr.ReleaseIdx, inUse: clr.InUse})
+	}
+	columnMan = &columnManager{c: s.Column}
+	unassignedNodes = make(map[string]*internalNode)
+	newSnapshotNode := func(sn *snapshotNode) *internalNode {
+		n := newNode(sn.ID, sn.Idx)
+		n.column = sn.Column
+		n.colorIdx = sn.ColorIdx
+		n.laneTip = sn.LaneTip
+		return n
+	}
+	open := make([]*internalNode, len(s.Open))
+	for i, sn := range s.Open {
+		open[i] = newSnapshotNode(sn)
+		unassignedNodes[sn.ID] = open[i]
+	}
+	closed := make([]*internalNode, len(s.Closed))
+	for i, sn := range s.Closed {
+		closed[i] = newSnapshotNode(sn)
+	}
+	stubs := make([]*internalNode, len(s.Stubs))
+	for i, st := range s.Stubs {
+		stubs[i] = newNode(st.ID, st.Idx)
+	}
+	resolve := func(ref snapshotRef) *internalNode {
+		switch ref[0] {
+		case refOpen:
+			return open[ref[1]]
+		case refClosed:
+			return closed[ref[1]]
+		}
+		return stubs[ref[1]]
+	}
+	for i, sn := range s.Closed {
+		child := closed[i]
+		for j, ref := range sn.Parents {
+			parent := resolve(ref)
+			child.parents = append(child.parents, parent)
+			path := &Path{colorIdx: sn.ColorsIdx[j], laneTip: sn.LaneTips[j], origin: child.id, movedBack: sn.MovedBack[j], newLane: sn.NewLane[j]}
+			for _, p := range sn.Paths[j] {
+				y := ptr(p[1])
+				if p[3] >= 0 {
+					y = open[p[3]].idx
+				}
+				path.Points = append(path.Points, newPoint(p[0], y, pointType(p[2])))
+			}
+			child.parentsPaths[parent.id] = path
+		}
+	}
+	followingNodes = newInternalNodeSet()
+	for i := len(s.Open) - 1; i >= 0; i-- {
+		for _, childIdx := range s.Open[i].Children {
+			open[i].children = append(open[i].children, closed[childIdx])
+		}
+		followingNodes.Add([]*internalNode{open[i]})
+	}
+	return s.TmpRow, unassignedNodes, followingNodes, columnMan, colorsMan
+}
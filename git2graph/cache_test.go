@@ -0,0 +1,110 @@
+package git2graph
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func serializePage(t *testing.T, out *Out) string {
+	by, err := json.Marshal(struct {
+		Nodes        []*Node
+		PartialPaths []*PartialPath
+	}{out.Nodes, out.PartialPaths})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(by)
+}
+
+// Every page laid out from a snapshot must be identical to the one laid out from row 0
+func testLayoutCacheSamePages(t *testing.T, file string, interval, step int) {
+	inputNodes, err := GetInputNodesFromFile(file)
+	if err != nil || len(inputNodes) < 4 {
+		return
+	}
+	cachePath := filepath.Join(t.TempDir(), "layout.json")
+	cache, _ := NewLayoutCache(cachePath, interval)
+	// Fill the cache
+	nodes, _ := GetInputNodesFromFile(file)
+	if _, err := GetPaginatedCached(nodes, "", -1, cache); err != nil {
+		t.Fatal(err)
+	}
+	for _, limit := range []int{2, 5} {
+		for i := 0; i < len(inputNodes)-1; i += step {
+			from := inputNodes[i].GetID()
+			nodes1, _ := GetInputNodesFromFile(file)
+			expected, _ := GetPaginated(nodes1, from, limit)
+			cache, _ = NewLayoutCache(cachePath, interval)
+			nodes2, _ := GetInputNodesFromFile(file)
+			actual, _ := GetPaginatedCached(nodes2, from, limit, cache)
+			if serializePage(t, expected) != serializePage(t, actual) {
+				t.Errorf("%s from %s limit %d:\n%s\n%s", file, from, limit, serializePage(t, expected), serializePage(t, actual))
+			}
+		}
+	}
+}
+
+func TestLayoutCacheSamePages(t *testing.T) {
+	files, _ := filepath.Glob("../data/test_*[0-9].json")
+	for _, file := range files {
+		testLayoutCacheSamePages(t, file, 3, 1)
+	}
+	testLayoutCacheSamePages(t, "../data/example_001.json", 50, 37)
+}
+
+func TestLayoutCacheInvalidated(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "layout.json")
+	cache, _ := NewLayoutCache(cachePath, 2)
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2"}},
+		{"id": "2", "parents": []string{"3"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}},
+	}
+	_, _ = GetPaginatedCached(inputNodes, "", -1, cache)
+	if cache.nearest(inputNodes, "4") == nil {
+		t.Fatal("expected a snapshot")
+	}
+	inputNodes[0] = &Node{"id": "0", "parents": []string{"2"}}
+	if cache.nearest(inputNodes, "4") != nil {
+		t.Fatal("expected the snapshot to be invalidated")
+	}
+	// Laying out the changed history refreshes the snapshot
+	_, _ = GetPaginatedCached(inputNodes, "", -1, cache)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache, _ = NewLayoutCache(cachePath, 2)
+	if s := cache.nearest(inputNodes, "4"); s == nil || s.Row != 2 {
+		t.Fatal("expected the refreshed snapshot")
+	}
+}
+
+func TestLayoutCacheInvalidatedByNextRow(t *testing.T) {
+	cache, _ := NewLayoutCache("", 2)
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2"}},
+		{"id": "2", "parents": []string{"3"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}},
+	}
+	_, _ = GetPaginatedCached(inputNodes, "", -1, cache)
+	// The layout of row 1 depends on the id of row 2
+	inputNodes[2] = &Node{"id": "5", "parents": []string{"4"}}
+	if cache.nearest(inputNodes, "4") != nil {
+		t.Fatal("expected the snapshot to be invalidated")
+	}
+}
+
+func TestLayoutCacheSaveUnalignedSnapshot(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "layout.json")
+	cache, _ := NewLayoutCache(cachePath, 2)
+	cache.record(&layoutSnapshot{Row: 3})
+	cache.record(&layoutSnapshot{Row: 2})
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache, _ = NewLayoutCache(cachePath, 2)
+	assertEq(t, 2, len(cache.snapshots))
+}
@@ -0,0 +1,156 @@
+package git2graph
+
+import "fmt"
+
+// Invariant is a geometric property every tree output must have
+type Invariant int
+
+const (
+	PathEndsInvariant     Invariant = iota // Paths start at their child and end at their parent
+	MonotonicInvariant                     // Points of a path never go up
+	NodesCellInvariant                     // No two nodes share a cell
+	CornersInvariant                       // Fork and MergeTo points are on the row of the previous point, MergeBack points on the row of the next one
+	LanesOverlapInvariant                  // Paths to different parents never go down the same column on the same rows
+)
+
+func (i Invariant) String() string {
+	switch i {
+	case PathEndsInvariant:
+		return "path ends"
+	case MonotonicInvariant:
+		return "monotonic"
+	case NodesCellInvariant:
+		return "nodes cell"
+	case CornersInvariant:
+		return "corners"
+	case LanesOverlapInvariant:
+		return "lanes overlap"
+	}
+	return "unknown"
+}
+
+// Violation is an invariant a tree output does not have
+type Violation struct {
+	Invariant Invariant
+	NodeID    string // Node the violation was found on
+	Message   string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: node %s: %s", v.Invariant, v.NodeID, v.Message)
+}
+
+type checkedPoint struct {
+	x   int
+	y   float64
+	typ pointType
+}
+
+// Return the points of the paths of a node of a tree output, with the positions of the rows when the time spacing is set
+func checkedPaths(node *Node) (paths [][]checkedPoint) {
+	for _, points := range outPaths(node) {
+		path := make([]checkedPoint, len(points))
+		for i, point := range points {
+			path[i].x, _, path[i].typ = outPoint(point)
+			switch y := point[1].(type) {
+			case int:
+				path[i].y = float64(y)
+			case float64:
+				path[i].y = y
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Return the cell of a node of a tree output, its column and the position of its row
+func checkedCell(node *Node) (int, float64) {
+	g := (*node)[gKey].([]any)
+	if len(g) > 4 {
+		if position, ok := g[4].(float64); ok {
+			return outColumn(node), position
+		}
+	}
+	return outColumn(node), float64(outRow(node))
+}
+
+// Check verifies the geometric invariants of a top-down tree output, and returns the ones it does not have.
+// Paths to parents missing from the output only have to start at their child, partial paths are not checked,
+// and the paths collapsed in the overflow lane of Options.MaxLanes may overlap.
+func Check(out *Out) (violations []Violation) {
+	violate := func(invariant Invariant, node *Node, format string, a ...any) {
+		violations = append(violations, Violation{invariant, node.GetID(), fmt.Sprintf(format, a...)})
+	}
+	type cell struct {
+		x int
+		y float64
+	}
+	byID := make(map[string]*Node, len(out.Nodes))
+	byCell := make(map[cell]*Node, len(out.Nodes))
+	for _, node := range out.Nodes {
+		byID[node.GetID()] = node
+		x, y := checkedCell(node)
+		if other, ok := byCell[cell{x, y}]; ok {
+			violate(NodesCellInvariant, node, "shares the cell %d,%v with node %s", x, y, other.GetID())
+		}
+		byCell[cell{x, y}] = node
+	}
+
+	type lane struct {
+		parent string
+		y1, y2 float64
+	}
+	lanes := make(map[int][]lane)
+	for _, node := range out.Nodes {
+		parents := node.GetParents()
+		g := (*node)[gKey].([]any)
+		for i, path := range checkedPaths(node) {
+			collapsed := len(g[3].([]any)[i].([]any)) > 2
+			if len(path) == 0 {
+				violate(PathEndsInvariant, node, "path to %s has no points", parents[i])
+				continue
+			}
+			x, y := checkedCell(node)
+			if first := path[0]; first.x != x || first.y != y {
+				violate(PathEndsInvariant, node, "path to %s starts at %d,%v", parents[i], first.x, first.y)
+			}
+			if parent, ok := byID[parents[i]]; ok {
+				x, y = checkedCell(parent)
+				if last := path[len(path)-1]; last.x != x || last.y != y {
+					violate(PathEndsInvariant, node, "path to %s ends at %d,%v", parents[i], last.x, last.y)
+				}
+			}
+			for j, point := range path {
+				if j > 0 && point.y < path[j-1].y {
+					violate(MonotonicInvariant, node, "path to %s goes up at point %d", parents[i], j)
+				}
+				switch point.typ {
+				case Fork, MergeTo:
+					if j == 0 || path[j-1].y != point.y {
+						violate(CornersInvariant, node, "path to %s turns down at point %d off the row of the previous point", parents[i], j)
+					}
+				case MergeBack:
+					if j == len(path)-1 || path[j+1].y != point.y {
+						violate(CornersInvariant, node, "path to %s turns across at point %d off the row of the next point", parents[i], j)
+					}
+				}
+				if j == 0 || path[j-1].y >= point.y || collapsed {
+					continue
+				}
+				// Like outSegments, the path goes down first after a MergeBack, and across first otherwise
+				prev, column := path[j-1], point.x
+				if prev.typ == MergeBack {
+					column = prev.x
+				}
+				for _, other := range lanes[column] {
+					if other.parent != parents[i] && other.y1 < point.y && prev.y < other.y2 {
+						violate(LanesOverlapInvariant, node, "path to %s overlaps the path to %s in column %d", parents[i], other.parent, column)
+					}
+				}
+				lanes[column] = append(lanes[column], lane{parents[i], prev.y, point.y})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,93 @@
+package git2graph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	node := func(id string, parents []string, row, column int, paths ...[][]any) *Node {
+		finalPaths := make([]any, len(paths))
+		for i, points := range paths {
+			finalPaths[i] = []any{"color1", points}
+		}
+		return &Node{idKey: id, parentsKey: parents, gKey: []any{ptr(row), column, "color1", finalPaths}}
+	}
+	out := &Out{Nodes: []*Node{
+		node("1", []string{"2", "3"}, 0, 0,
+			[][]any{{0, 0, Pipe}, {0, 1, Pipe}},
+			[][]any{{0, 0, Pipe}, {1, 1, Fork}, {1, 3, Pipe}}),
+		node("2", []string{"4"}, 1, 0, [][]any{{0, 1, Pipe}, {0, 3, Pipe}}),
+		node("3", []string{"4"}, 2, 1, [][]any{{1, 2, Pipe}, {0, 2, Pipe}, {0, 1, Pipe}}),
+		node("4", []string{}, 3, 0),
+		node("5", []string{}, 3, 0),
+	}}
+	violations := Check(out)
+	expected := []Violation{
+		{NodesCellInvariant, "5", "shares the cell 0,3 with node 4"},
+		{PathEndsInvariant, "1", "path to 3 ends at 1,3"},
+		{CornersInvariant, "1", "path to 3 turns down at point 1 off the row of the previous point"},
+		{PathEndsInvariant, "3", "path to 4 ends at 0,1"},
+		{MonotonicInvariant, "3", "path to 4 goes up at point 2"},
+	}
+	assertEq(t, len(expected), len(violations))
+	for i := range expected {
+		assertEq(t, expected[i], violations[i])
+	}
+
+	out = &Out{Nodes: []*Node{
+		node("1", []string{"2", "3"}, 0, 0,
+			[][]any{{0, 0, Pipe}, {0, 1, Pipe}},
+			[][]any{{0, 0, Pipe}, {0, 2, Pipe}}),
+		node("2", []string{"3"}, 1, 0),
+		node("3", []string{}, 2, 0),
+	}}
+	violations = Check(out)
+	assertEq(t, 1, len(violations))
+	assertEq(t, "lanes overlap: node 1: path to 3 overlaps the path to 2 in column 0", violations[0].String())
+}
+
+// Every lane assigner keeps the invariants on the fixtures in topological order, for the whole layout and for pages
+func TestCheckCorpus(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		if len(inputNodes) < 2 || !isTopological(inputNodes) {
+			continue
+		}
+		for _, assigner := range LaneAssigners() {
+			for _, opts := range []*Options{
+				{LaneAssigner: assigner},
+				{LaneAssigner: assigner, From: inputNodes[0].GetID(), Limit: 4},
+				{LaneAssigner: assigner, MaxLanes: 2},
+			} {
+				inputNodes, _ = GetInputNodesFromFile(file)
+				out, err := LayoutContext(context.Background(), inputNodes, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, violation := range Check(out) {
+					t.Errorf("%s %s: %s", file, assigner.Name(), violation)
+				}
+			}
+		}
+	}
+}
+
+// Return either or not every node comes before its parents
+func isTopological(inputNodes []*Node) bool {
+	seen := make(map[string]bool, len(inputNodes))
+	for _, node := range inputNodes {
+		if seen[node.GetID()] {
+			return false
+		}
+		for _, parent := range node.GetParents() {
+			if seen[parent] {
+				return false
+			}
+		}
+		seen[node.GetID()] = true
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package git2graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// ExportFixture returns an anonymized copy of the nodes needed to reproduce the layout of a page, and the id of from in it.
+// The layout of a page depends on the rows above it and on the row right below it, the fixture has the rows
+// from the first one to the row below the page. The parents of these rows that are further down are added after them,
+// so that the page has the boundaries of the full history.
+// Ids are replaced by sequential ids (see SequentialIDs), and every property but the id and the parents is dropped.
+func ExportFixture(inputNodes []*Node, from string, limit int) (fixture []*Node, fixtureFrom string, err error) {
+	fromIdx := -1
+	if from != "" {
+		fromIdx = slices.IndexFunc(inputNodes, func(node *Node) bool { return node.GetID() == from })
+		if fromIdx == -1 {
+			return nil, "", fmt.Errorf("commit %s not found", from)
+		}
+	}
+	end := len(inputNodes)
+	if limit > 0 {
+		end = min(end, fromIdx+1+limit+utils.Ternary(from != "", 1, 0))
+	}
+	parents := make(map[string]bool)
+	for _, node := range inputNodes[:end] {
+		for _, parent := range node.GetParents() {
+			parents[parent] = true
+		}
+	}
+	fixture = make([]*Node, 0, end)
+	for i, node := range inputNodes {
+		if i < end || parents[node.GetID()] {
+			fixture = append(fixture, &Node{idKey: node.GetID(), parentsKey: slices.Clone(node.GetParents())})
+		}
+	}
+	fixture = SequentialIDs(fixture)
+	if from != "" {
+		fixtureFrom = fixture[fromIdx].GetID()
+	}
+	return fixture, fixtureFrom, nil
+}
+
+// WriteFixture writes nodes in the format of the data/test_NNN.json files, one node per line
+func WriteFixture(w io.Writer, nodes []*Node) error {
+	var sb strings.Builder
+	sb.WriteString("[\n")
+	for i, node := range nodes {
+		parents := make([]string, len(node.GetParents()))
+		for j, parent := range node.GetParents() {
+			parents[j] = jsonString(parent)
+		}
+		sb.WriteString(fmt.Sprintf(`  {"id": %s, "parents": [%s]}`, jsonString(node.GetID()), strings.Join(parents, ", ")))
+		sb.WriteString(utils.Ternary(i < len(nodes)-1, ",\n", "\n"))
+	}
+	sb.WriteString("]\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package git2graph
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Assert that the page from of the fixture has the layout of the page from of the original nodes
+func assertFixturePage(t *testing.T, name string, inputNodes []*Node, from, limit int) {
+	t.Helper()
+	fromID := ""
+	if from >= 0 {
+		fromID = inputNodes[from].GetID()
+	}
+	fixture, fixtureFrom, err := ExportFixture(inputNodes, fromID, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := LayoutContext(context.Background(), inputNodes, &Options{From: fromID, Limit: limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := LayoutContext(context.Background(), fixture, &Options{From: fixtureFrom, Limit: limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expected.Nodes) != len(actual.Nodes) {
+		t.Fatalf("%s from %d limit %d: expected %d nodes, got %d", name, from, limit, len(expected.Nodes), len(actual.Nodes))
+	}
+	for i := range expected.Nodes {
+		if e, a := (*expected.Nodes[i])[gKey], (*actual.Nodes[i])[gKey]; !reflect.DeepEqual(e, a) {
+			t.Fatalf("%s from %d limit %d row %d\nExpected: %v\nActual:   %v", name, from, limit, i, e, a)
+		}
+		e, _ := (*expected.Nodes[i])[boundariesKey].([]*Boundary)
+		a, _ := (*actual.Nodes[i])[boundariesKey].([]*Boundary)
+		if len(e) != len(a) {
+			t.Fatalf("%s from %d limit %d row %d: expected %d boundaries, got %d", name, from, limit, i, len(e), len(a))
+		}
+		for j := range e {
+			assertEq(t, e[j].Lane, a[j].Lane)
+		}
+	}
+	if !reflect.DeepEqual(expected.PartialPaths, actual.PartialPaths) {
+		t.Fatalf("%s from %d limit %d\nExpected partial paths: %v\nActual:   %v", name, from, limit, expected.PartialPaths, actual.PartialPaths)
+	}
+}
+
+// The page of a fixture has the layout of the page of the original nodes
+func TestExportFixturePages(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		for _, from := range []int{-1, 0, 3, 8} {
+			for _, limit := range []int{-1, 1, 4} {
+				if from < len(inputNodes)-1 {
+					assertFixturePage(t, file, inputNodes, from, limit)
+				}
+			}
+		}
+	}
+}
+
+// Pages of 10 rows every 5 rows of a long history, whose pages have many paths coming from the rows above
+func TestExportFixtureLongHistoryPages(t *testing.T) {
+	inputNodes, err := GetInputNodesFromFile("../data/example_001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for from := -1; from < len(inputNodes)-1; from += 5 {
+		assertFixturePage(t, "example_001.json", inputNodes, from, 10)
+	}
+}
+
+// Fixtures keep only the ids and parents, renamed in rows order, and are read back as json input
+func TestExportFixtureAnonymized(t *testing.T) {
+	inputNodes := []*Node{
+		{idKey: "d4", parentsKey: []string{"c3"}},
+		{idKey: "c3", parentsKey: []string{"b2", "x"}, subjectKey: "secret", authorEmailKey: "alice@example.com"},
+		{idKey: "b2", parentsKey: []string{"a1"}, decorateKey: " (main)"},
+		{idKey: "a1", parentsKey: []string{"a0"}},
+		{idKey: "a0", parentsKey: []string{}},
+		{idKey: "e5", parentsKey: []string{}},
+	}
+	fixture, from, err := ExportFixture(inputNodes, "d4", 1)
+	assertEq(t, nil, err)
+	assertEq(t, "0", from)
+	var buf bytes.Buffer
+	assertEq(t, nil, WriteFixture(&buf, fixture))
+	// The row below the page is b2, and its parent a1 is kept for the boundaries
+	assertEq(t, `[
+  {"id": "0", "parents": ["1"]},
+  {"id": "1", "parents": ["2", "4"]},
+  {"id": "2", "parents": ["3"]},
+  {"id": "3", "parents": ["5"]}
+]
+`, buf.String())
+	decoded, err := GetInputNodesFromJSON(buf.Bytes())
+	assertEq(t, nil, err)
+	assertEq(t, 4, len(decoded))
+	assertEq(t, "c3", inputNodes[1].GetID())
+
+	_, _, err = ExportFixture(inputNodes, "z", 1)
+	assertEq(t, "commit z not found", err.Error())
+}
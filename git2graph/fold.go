@@ -0,0 +1,229 @@
+package git2graph
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+const foldedKey = "folded" // Ids of the commits folded into a placeholder node
+
+// Fold the input according to opts, before the layout.
+// A placeholder node takes the id of the first commit it folds, so the page boundaries stay valid,
+// and opts.From is replaced by the placeholder of the commit it names when that commit is folded.
+func foldInput(inputNodes []*Node, opts *Options) ([]*Node, *Options) {
+	foldedInto := make(map[string]string)
+	if opts.FoldMergedBranches {
+		inputNodes = foldMergedBranches(inputNodes, foldedInto)
+	}
+	inputNodes = foldLinearRuns(inputNodes, opts.FoldLinearRuns, foldedInto)
+	if placeholder, ok := foldedInto[opts.From]; ok {
+		optsCopy := *opts
+		optsCopy.From = placeholder
+		opts = &optsCopy
+	}
+	return inputNodes, opts
+}
+
+// Return a placeholder node for the folded nodes, in rows order. It has the refs of the folded nodes.
+func newFoldedNode(folded []*Node, parents []string, foldedInto map[string]string) *Node {
+	ids := make([]string, len(folded))
+	var refs []string
+	for i, node := range folded {
+		ids[i] = node.GetID()
+		foldedInto[ids[i]] = ids[0]
+		refs = append(refs, node.GetRefs()...)
+	}
+	placeholder := &Node{
+		idKey:      ids[0],
+		parentsKey: parents,
+		foldedKey:  ids,
+		subjectKey: utils.Ternary(len(folded) == 1, "1 commit", fmt.Sprintf("%d commits", len(folded))),
+	}
+	if len(refs) > 0 {
+		(*placeholder)[refsKey] = refs
+	}
+	return placeholder
+}
+
+func isFolded(node *Node) bool {
+	_, ok := (*node)[foldedKey]
+	return ok
+}
+
+// Fold the runs of at least minRun consecutive commits having a single parent, the commit on the next row,
+// and a single child, the commit on the previous row. Commits with refs, and placeholders, are never folded.
+func foldLinearRuns(inputNodes []*Node, minRun int, foldedInto map[string]string) []*Node {
+	if minRun <= 0 {
+		return inputNodes
+	}
+	minRun = max(minRun, 2)
+	nbChildren := make(map[string]int, len(inputNodes))
+	for _, node := range inputNodes {
+		for _, parent := range node.GetParents() {
+			nbChildren[parent]++
+		}
+	}
+	foldable := func(i int) bool {
+		if i == 0 || i == len(inputNodes)-1 {
+			return false
+		}
+		node := inputNodes[i]
+		parents := node.GetParents()
+		return len(parents) == 1 && parents[0] == inputNodes[i+1].GetID() &&
+			nbChildren[node.GetID()] == 1 && hasParent(inputNodes[i-1], node.GetID()) &&
+			len(node.GetRefs()) == 0 && !isFolded(node)
+	}
+	out := make([]*Node, 0, len(inputNodes))
+	for i := 0; i < len(inputNodes); {
+		j := i
+		for j < len(inputNodes) && foldable(j) {
+			j++
+		}
+		switch {
+		case j == i:
+			out = append(out, inputNodes[i])
+			j++
+		case j-i < minRun:
+			out = append(out, inputNodes[i:j]...)
+		default:
+			out = append(out, newFoldedNode(inputNodes[i:j], inputNodes[j-1].GetParents(), foldedInto))
+		}
+		i = j
+	}
+	return out
+}
+
+func hasParent(node *Node, parentID string) bool {
+	for _, parent := range node.GetParents() {
+		if parent == parentID {
+			return true
+		}
+	}
+	return false
+}
+
+// Fold the commits reachable only through the second parents of merges, the merged branches.
+// The commits of a merged branch are replaced by a placeholder node on the row of the tip of the branch,
+// whose parents are the commits the branch forked from, so the lane of the branch is kept as a short segment.
+// Fork points come from the first parents only, the merges of the mainline into a branch are not kept.
+// Commits on the first-parent chains of the commits without children (the mainline) are never folded,
+// their parents in a merged branch are replaced by its placeholder, unless it is above them.
+func foldMergedBranches(inputNodes []*Node, foldedInto map[string]string) []*Node {
+	byID := make(map[string]*Node, len(inputNodes))
+	rows := make(map[string]int, len(inputNodes))
+	hasChild := make(map[string]bool, len(inputNodes))
+	for i, node := range inputNodes {
+		byID[node.GetID()] = node
+		rows[node.GetID()] = i
+		for _, parent := range node.GetParents() {
+			hasChild[parent] = true
+		}
+	}
+	mainline := make(map[string]bool)
+	for _, node := range inputNodes {
+		if hasChild[node.GetID()] {
+			continue
+		}
+		for cur := node; cur != nil && !mainline[cur.GetID()]; {
+			mainline[cur.GetID()] = true
+			parents := cur.GetParents()
+			if len(parents) == 0 {
+				break
+			}
+			cur = byID[parents[0]]
+		}
+	}
+	isHidden := func(id string) bool {
+		_, ok := byID[id]
+		return ok && !mainline[id]
+	}
+
+	// Each hidden commit belongs to the branch of the first merge, in rows order, reaching it
+	branchOf := make(map[string]string)
+	for _, node := range inputNodes {
+		if !mainline[node.GetID()] {
+			continue
+		}
+		for _, tip := range node.GetParents() {
+			if !isHidden(tip) || branchOf[tip] != "" {
+				continue
+			}
+			branchOf[tip] = tip
+			for stack := []string{tip}; len(stack) > 0; {
+				id := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, parent := range byID[id].GetParents() {
+					if isHidden(parent) && branchOf[parent] == "" {
+						branchOf[parent] = tip
+						stack = append(stack, parent)
+					}
+				}
+			}
+		}
+	}
+	branches := make(map[string][]*Node)
+	for _, node := range inputNodes {
+		if tip := branchOf[node.GetID()]; tip != "" {
+			branches[tip] = append(branches[tip], node)
+		}
+	}
+
+	out := make([]*Node, 0, len(inputNodes))
+	for _, node := range inputNodes {
+		id := node.GetID()
+		if !isHidden(id) {
+			out = append(out, withPlaceholderParents(node, rows, isHidden, branchOf))
+			continue
+		}
+		if branchOf[id] != id {
+			continue
+		}
+		// Parents of the branch: the commits it forked from (first parents leaving the branch),
+		// the merges of the mainline into the branch are dropped
+		parents := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, folded := range branches[id] {
+			foldedParents := folded.GetParents()
+			if len(foldedParents) == 0 {
+				continue
+			}
+			parent := foldedParents[0]
+			if isHidden(parent) {
+				if parent = branchOf[parent]; parent == id || rows[parent] <= rows[id] {
+					continue
+				}
+			}
+			if !seen[parent] {
+				seen[parent] = true
+				parents = append(parents, parent)
+			}
+		}
+		out = append(out, newFoldedNode(branches[id], parents, foldedInto))
+	}
+	return out
+}
+
+// Return the node with its parents in merged branches replaced by the placeholders of the branches,
+// a copy if any parent changes
+func withPlaceholderParents(node *Node, rows map[string]int, isHidden func(string) bool, branchOf map[string]string) *Node {
+	parents := make([]string, 0, len(node.GetParents()))
+	for _, parent := range node.GetParents() {
+		if isHidden(parent) {
+			if parent = branchOf[parent]; rows[parent] <= rows[node.GetID()] {
+				continue
+			}
+		}
+		if !slices.Contains(parents, parent) {
+			parents = append(parents, parent)
+		}
+	}
+	if slices.Equal(parents, node.GetParents()) {
+		return node
+	}
+	copied := maps.Clone(*node)
+	copied[parentsKey] = parents
+	return &copied
+}
@@ -0,0 +1,104 @@
+package git2graph
+
+import (
+	"context"
+	"testing"
+)
+
+func newFoldInput() []*Node {
+	return []*Node{
+		{"id": "1", "parents": []string{"2", "7"}},
+		{"id": "2", "parents": []string{"3"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{"5"}, "refs": []any{"v1.0"}},
+		{"id": "5", "parents": []string{"6"}},
+		{"id": "6", "parents": []string{"7"}},
+		{"id": "7", "parents": []string{"8"}},
+		{"id": "8", "parents": []string{"9"}},
+		{"id": "9", "parents": []string{}},
+	}
+}
+
+func TestFoldLinearRuns(t *testing.T) {
+	out, _ := LayoutContext(context.Background(), newFoldInput(), &Options{FoldLinearRuns: 2})
+	// 4 has a ref, 7 has two children, and 8 is a run shorter than 2
+	expectedIDs := []string{"1", "2", "4", "5", "7", "8", "9"}
+	expectedFolded := map[string][]string{"2": {"2", "3"}, "5": {"5", "6"}}
+	assertEq(t, len(expectedIDs), len(out.Nodes))
+	for idx, node := range out.Nodes {
+		assertEq(t, expectedIDs[idx], node.GetID())
+		folded, _ := (*node)[foldedKey].([]string)
+		assertEq(t, len(expectedFolded[node.GetID()]), len(folded))
+		for i, id := range folded {
+			assertEq(t, expectedFolded[node.GetID()][i], id)
+		}
+	}
+	assertEq(t, "2 commits", (*out.Nodes[1])[subjectKey])
+	assertEq(t, "7", out.Nodes[3].GetParents()[0])
+	validateColumns(t, []int{0, 0, 0, 0, 0, 0, 0}, out.Nodes)
+}
+
+func TestFoldLinearRunsPaginated(t *testing.T) {
+	// From a folded commit, the page starts after its placeholder
+	out, _ := LayoutContext(context.Background(), newFoldInput(), &Options{FoldLinearRuns: 2, From: "3", Limit: 2})
+	assertEq(t, 2, len(out.Nodes))
+	assertEq(t, "4", out.Nodes[0].GetID())
+	assertEq(t, "5", out.Nodes[1].GetID())
+	assertEq(t, 2, *(*out.Nodes[0])[gKey].([]any)[0].(*int))
+}
+
+func TestFoldMergedBranches(t *testing.T) {
+	newInput := func() []*Node {
+		return []*Node{
+			{"id": "1", "parents": []string{"2", "4"}}, // Merge of the feature branch 4-5
+			{"id": "2", "parents": []string{"3"}},
+			{"id": "4", "parents": []string{"5", "2"}}, // Merge of the mainline into the feature branch
+			{"id": "5", "parents": []string{"3"}},
+			{"id": "3", "parents": []string{}},
+		}
+	}
+	out, _ := LayoutContext(context.Background(), newInput(), &Options{FoldMergedBranches: true})
+	expectedIDs := []string{"1", "2", "4", "3"}
+	assertEq(t, len(expectedIDs), len(out.Nodes))
+	for idx, node := range out.Nodes {
+		assertEq(t, expectedIDs[idx], node.GetID())
+	}
+	placeholder := out.Nodes[2]
+	assertEq(t, "2 commits", (*placeholder)[subjectKey])
+	assertEq(t, 2, len((*placeholder)[foldedKey].([]string)))
+	assertEq(t, 1, len(placeholder.GetParents()))
+	assertEq(t, "3", placeholder.GetParents()[0])
+	validateColumns(t, []int{0, 0, 1, 0}, out.Nodes)
+
+	// From a folded commit
+	out, _ = LayoutContext(context.Background(), newInput(), &Options{FoldMergedBranches: true, From: "5", Limit: 1})
+	assertEq(t, 1, len(out.Nodes))
+	assertEq(t, "3", out.Nodes[0].GetID())
+}
+
+func TestFoldMergedBranchesParents(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "M1", "parents": []string{"M2", "T1"}},
+		{"id": "M2", "parents": []string{"M3", "T2"}}, // Merge of a commit of the branch folded into T1
+		{"id": "T1", "parents": []string{"T2"}, "refs": []any{"feature"}},
+		{"id": "T2", "parents": []string{"M3"}, "refs": []any{"v1.0"}},
+		{"id": "M3", "parents": []string{}},
+	}
+	out, _ := LayoutContext(context.Background(), inputNodes, &Options{FoldMergedBranches: true})
+	expectedIDs := []string{"M1", "M2", "T1", "M3"}
+	assertEq(t, len(expectedIDs), len(out.Nodes))
+	for idx, node := range out.Nodes {
+		assertEq(t, expectedIDs[idx], node.GetID())
+		assertBoundaries(t, node)
+	}
+	parents := out.Nodes[1].GetParents()
+	assertEq(t, 2, len(parents))
+	assertEq(t, "M3", parents[0])
+	assertEq(t, "T1", parents[1])
+	refs := out.Nodes[2].GetRefs()
+	assertEq(t, 2, len(refs))
+	assertEq(t, "feature", refs[0])
+	assertEq(t, "v1.0", refs[1])
+	// The input is left untouched
+	assertEq(t, "T2", inputNodes[1].GetParents()[1])
+}
@@ -0,0 +1,244 @@
+package git2graph
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/alaingilbert/git2graph/git2graph/gen"
+	"github.com/sirupsen/logrus"
+)
+
+// Fuzz inputs are a header of 3 bytes, then a record per node, in rows order:
+//   - header: the row of Options.From (none if past the last node), Options.Limit, and the options flags below
+//   - node: the number of parents in the 3 low bits, fuzzRefFlag for a "main" ref, then a byte per parent,
+//     the distance to the row of the parent minus one. Parents past the last row are missing from the input.
+//
+// Parents are always below their child, so every input is a valid DAG.
+const (
+	fuzzCompactFlag = 1 << iota
+	fuzzMaxLanesFlag
+	fuzzFoldRunsFlag
+	fuzzFoldMergedFlag
+	fuzzPriorityFlag
+	fuzzRowsFlag
+	fuzzBottomUpFlag
+)
+
+const (
+	fuzzParentsMask = 0b111
+	fuzzRefFlag     = 1 << 3
+	fuzzMaxNodes    = 200
+)
+
+// Decode a fuzz input into input nodes and options
+func fuzzInput(data []byte) ([]*Node, *Options) {
+	opts := &Options{}
+	if len(data) < 3 {
+		return nil, opts
+	}
+	fromRow, limit, flags := int(data[0]), int(data[1]%16), data[2]
+	type record struct {
+		offsets []int
+		ref     bool
+	}
+	var records []record
+	for i := 3; i < len(data) && len(records) < fuzzMaxNodes; {
+		r := record{ref: data[i]&fuzzRefFlag != 0}
+		nbParents := int(data[i] & fuzzParentsMask)
+		i++
+		for ; nbParents > 0 && i < len(data); nbParents-- {
+			r.offsets = append(r.offsets, int(data[i])+1)
+			i++
+		}
+		records = append(records, r)
+	}
+	inputNodes := make([]*Node, len(records))
+	for row, r := range records {
+		parents := make([]string, 0, len(r.offsets))
+		seen := make(map[string]bool)
+		for _, offset := range r.offsets {
+			if parent := strconv.Itoa(row + offset); !seen[parent] {
+				seen[parent] = true
+				parents = append(parents, parent)
+			}
+		}
+		inputNodes[row] = &Node{idKey: strconv.Itoa(row), parentsKey: parents}
+		if r.ref {
+			(*inputNodes[row])[refsKey] = []any{"main"}
+		}
+	}
+
+	if fromRow < len(inputNodes) {
+		opts.From = inputNodes[fromRow].GetID()
+	}
+	opts.Limit = limit
+	if flags&fuzzCompactFlag != 0 {
+		opts.LaneAssigner = CompactLanes
+	}
+	if flags&fuzzMaxLanesFlag != 0 {
+		opts.MaxLanes = 2
+	}
+	if flags&fuzzFoldRunsFlag != 0 {
+		opts.FoldLinearRuns = 2
+	}
+	opts.FoldMergedBranches = flags&fuzzFoldMergedFlag != 0
+	if flags&fuzzPriorityFlag != 0 {
+		opts.PriorityRefs = []string{"main"}
+	}
+	if flags&fuzzBottomUpFlag != 0 {
+		opts.Orientation = BottomUp
+	}
+	return inputNodes, opts
+}
+
+// Encode input nodes in topological order into a fuzz input, false if they do not fit
+func fuzzEncode(inputNodes []*Node, fromRow, limit int, flags byte) ([]byte, bool) {
+	rows := make(map[string]int, len(inputNodes))
+	for row, node := range inputNodes {
+		rows[node.GetID()] = row
+	}
+	data := []byte{byte(fromRow), byte(limit), flags}
+	missing := len(inputNodes)
+	missingRows := make(map[string]int)
+	for row, node := range inputNodes {
+		parents := node.GetParents()
+		if len(parents) > fuzzParentsMask {
+			return nil, false
+		}
+		b := byte(len(parents))
+		if len(node.GetRefs()) > 0 {
+			b |= fuzzRefFlag
+		}
+		data = append(data, b)
+		for _, parent := range parents {
+			parentRow, ok := rows[parent]
+			if !ok {
+				if parentRow, ok = missingRows[parent]; !ok {
+					parentRow = missing
+					missingRows[parent] = missing
+					missing++
+				}
+			}
+			offset := parentRow - row - 1
+			if offset < 0 || offset > 255 {
+				return nil, false
+			}
+			data = append(data, byte(offset))
+		}
+	}
+	return data, true
+}
+
+// Turn the log.Fatal calls of the layout into panics for the duration of the test
+func failOnFatal(t testing.TB) {
+	logger := logrus.StandardLogger()
+	exitFunc := logger.ExitFunc
+	logger.ExitFunc = func(code int) { panic(fmt.Sprintf("log.Fatal, exit code %d", code)) }
+	t.Cleanup(func() { logger.ExitFunc = exitFunc })
+}
+
+func FuzzLayout(f *testing.F) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		if len(inputNodes) > fuzzMaxNodes || !isTopological(inputNodes) {
+			continue
+		}
+		for _, flags := range []byte{0, fuzzCompactFlag, fuzzRowsFlag} {
+			if data, ok := fuzzEncode(inputNodes, 255, 0, flags); ok {
+				f.Add(data)
+			}
+		}
+		if data, ok := fuzzEncode(inputNodes, 1, 3, fuzzMaxLanesFlag|fuzzPriorityFlag); ok {
+			f.Add(data)
+		}
+	}
+	// Generated histories
+	for _, seed := range []int64{1, 2, 3, 4, 5, 11} {
+		cfg := gen.DefaultConfig
+		cfg.Seed, cfg.Commits, cfg.Orphans = seed, fuzzMaxNodes, 1
+		for _, flags := range []byte{0, fuzzCompactFlag} {
+			if data, ok := fuzzEncode(generateNodes(cfg), 255, 0, flags); ok {
+				f.Add(data)
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		failOnFatal(t)
+		inputNodes, opts := fuzzInput(data)
+		if len(inputNodes) == 0 {
+			return
+		}
+		layout := LayoutContext
+		if data[2]&fuzzRowsFlag != 0 {
+			layout = LayoutRowsContext
+		}
+		out, err := layout(context.Background(), inputNodes, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data[2]&fuzzRowsFlag != 0 || opts.Orientation != TopDown {
+			return
+		}
+		for _, violation := range Check(out) {
+			t.Error(violation)
+		}
+	})
+}
+
+// The seed corpus decodes back to the fixtures
+func TestFuzzEncode(t *testing.T) {
+	inputNodes, _ := GetInputNodesFromFile("../data/test_010.json")
+	data, ok := fuzzEncode(inputNodes, 2, 5, fuzzCompactFlag)
+	assertEq(t, true, ok)
+	decoded, opts := fuzzInput(data)
+	assertEq(t, len(inputNodes), len(decoded))
+	assertEq(t, decoded[2].GetID(), opts.From)
+	assertEq(t, 5, opts.Limit)
+	assertEq(t, CompactLanes, opts.LaneAssigner)
+	rows := make(map[string]int)
+	for row, node := range inputNodes {
+		rows[node.GetID()] = row
+	}
+	for row, node := range inputNodes {
+		parents := decoded[row].GetParents()
+		assertEq(t, len(node.GetParents()), len(parents))
+		for i, parent := range node.GetParents() {
+			if parentRow, ok := rows[parent]; ok {
+				assertEq(t, strconv.Itoa(parentRow), parents[i])
+			}
+		}
+	}
+}
+
+// Lanes overlaps found in generated histories
+func TestLanesOverlapRegressions(t *testing.T) {
+	tests := []map[int][]int{
+		// A child forking into the lane of a parent, and opening a new lane for another parent
+		{0: {1, 4, 5}, 1: {2, 4, 3}, 2: {4}, 3: {}, 4: {6}, 5: {}, 6: {}},
+		// A path moved back into the column of its parent by the merge of an orphan
+		{0: {3, 2, 5}, 1: {4}, 2: {4}, 3: {}, 4: {6}, 5: {}, 6: {}},
+		// A node moved left by the second child merging into the current node
+		{0: {2, 5}, 1: {7, 9}, 2: {6, 4}, 3: {7}, 4: {6}, 5: {6}, 6: {8}, 7: {}, 8: {}, 9: {}},
+	}
+	for _, tt := range tests {
+		inputNodes := make([]*Node, len(tt))
+		for row := range inputNodes {
+			parents := make([]string, len(tt[row]))
+			for i, parent := range tt[row] {
+				parents[i] = strconv.Itoa(parent)
+			}
+			inputNodes[row] = &Node{idKey: strconv.Itoa(row), parentsKey: parents}
+		}
+		out, err := LayoutContext(context.Background(), inputNodes, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, violation := range Check(out) {
+			t.Error(violation)
+		}
+	}
+}
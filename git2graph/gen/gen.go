@@ -0,0 +1,285 @@
+// Package gen generates deterministic synthetic commit histories, in the json input format of git2graph,
+// for benchmarks and fuzzing.
+package gen
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// Config of a generated history. Rates are the probabilities of an event at each generated commit.
+type Config struct {
+	Seed           int64   // Same seed and config, same history
+	Commits        int     // Number of commits
+	Branches       int     // Number of long-lived branches besides main
+	FeatureRate    float64 // Starting a feature branch off a long-lived branch
+	FeatureLength  int     // Average number of commits of a feature branch before it is merged
+	MergeBackRate  float64 // Merging a long-lived branch back into main, or main into it
+	OctopusRate    float64 // Merging all the finished feature branches of a long-lived branch at once
+	CherryPickRate float64 // Copying the last commits of a feature branch onto another long-lived branch
+	Orphans        int     // Number of additional root commits, each one starting a long-lived branch
+}
+
+// DefaultConfig is a thousand commits of main and two long-lived branches, with feature branches
+var DefaultConfig = Config{
+	Seed:           1,
+	Commits:        1000,
+	Branches:       2,
+	FeatureRate:    0.1,
+	FeatureLength:  4,
+	MergeBackRate:  0.02,
+	OctopusRate:    0.01,
+	CherryPickRate: 0.01,
+}
+
+const (
+	startTimestamp = 1577836800 // 2020-01-01
+	maxParents     = 7          // Parents of an octopus merge
+	maxCherryPicks = 3          // Commits copied by a cherry-pick
+)
+
+var authors = []string{"Alice", "Bob", "Carol", "Dave", "Eve"}
+
+type branch struct {
+	name     string
+	root     bool   // The first commit of the branch has no parent
+	head     string // Id of the last commit, empty until the branch has one
+	nbCommit int
+	base     *branch  // Branch a feature is merged into, nil for long-lived branches
+	length   int      // Number of commits of a feature before it is merged
+	subjects []string // Subjects of the commits of a feature
+	merged   string   // Head of a long-lived branch last merged into main
+	synced   string   // Head of main last merged into a long-lived branch
+}
+
+// Return either or not a feature branch has all its commits and can be merged
+func (b *branch) finished() bool {
+	return b.base != nil && len(b.subjects) >= b.length
+}
+
+type generator struct {
+	cfg       Config
+	rng       *rand.Rand
+	commits   []map[string]any
+	longLived []*branch // main first
+	features  []*branch
+	timestamp int64
+	nbFeature int
+}
+
+// Generate returns the commits of a synthetic history, newest first like git log.
+// Commits have the id, parents, name, email, timestamp and subject properties, and the heads of the branches have refs.
+func Generate(cfg Config) []map[string]any {
+	g := &generator{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), timestamp: startTimestamp}
+	if cfg.Commits <= 0 {
+		return []map[string]any{}
+	}
+	orphansAt := make(map[int]bool)
+	for _, i := range g.rng.Perm(cfg.Commits - 1)[:min(max(cfg.Orphans, 0), cfg.Commits-1)] {
+		orphansAt[1+i] = true
+	}
+	main := &branch{name: "main", root: true}
+	g.longLived = append(g.longLived, main)
+	for i := 0; i < cfg.Branches; i++ {
+		g.longLived = append(g.longLived, &branch{name: utils.Ternary(i == 0, "develop", "release/"+strconv.Itoa(i))})
+	}
+	g.commit(main, "Initial commit")
+	for len(g.commits) < cfg.Commits {
+		if orphansAt[len(g.commits)] {
+			orphan := &branch{name: "orphan/" + strconv.Itoa(len(g.longLived)-cfg.Branches), root: true}
+			g.longLived = append(g.longLived, orphan)
+			g.commit(orphan, "Initial commit of "+orphan.name)
+			continue
+		}
+		done := false
+		switch u := g.rng.Float64(); {
+		case u < cfg.OctopusRate:
+			done = g.octopus()
+		case u < cfg.OctopusRate+cfg.MergeBackRate:
+			done = g.mergeBack()
+		case u < cfg.OctopusRate+cfg.MergeBackRate+cfg.CherryPickRate:
+			done = g.cherryPick()
+		case u < cfg.OctopusRate+cfg.MergeBackRate+cfg.CherryPickRate+cfg.FeatureRate:
+			done = g.startFeature()
+		}
+		if !done {
+			g.work()
+		}
+	}
+	g.setRefs()
+	for i, j := 0, len(g.commits)-1; i < j; i, j = i+1, j-1 {
+		g.commits[i], g.commits[j] = g.commits[j], g.commits[i]
+	}
+	return g.commits
+}
+
+// Add a commit on top of the branch, with the head of the branch as first parent.
+// A branch without commits forks from the head of its base, or of main, unless it starts a root commit.
+func (g *generator) commit(b *branch, subject string, otherParents ...string) {
+	parents := make([]string, 0, 1+len(otherParents))
+	if b.head != "" {
+		parents = append(parents, b.head)
+	} else if b.base != nil {
+		parents = append(parents, b.base.head)
+	} else if !b.root {
+		parents = append(parents, g.longLived[0].head)
+	}
+	parents = append(parents, otherParents...)
+	// Mostly minutes to hours in between two commits, sometimes idle days
+	g.timestamp += 60 + g.rng.Int63n(4*3600)
+	if g.rng.Intn(20) == 0 {
+		g.timestamp += g.rng.Int63n(14 * 24 * 3600)
+	}
+	author := authors[g.rng.Intn(len(authors))]
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d:%d", g.cfg.Seed, len(g.commits))))
+	id := hex.EncodeToString(sum[:])
+	g.commits = append(g.commits, map[string]any{
+		"id":        id,
+		"parents":   parents,
+		"name":      author,
+		"email":     strings.ToLower(author) + "@example.com",
+		"timestamp": g.timestamp,
+		"subject":   subject,
+	})
+	b.head = id
+	b.nbCommit++
+	if b.base != nil {
+		b.subjects = append(b.subjects, subject)
+	}
+}
+
+// Commit on a random branch, a finished feature is merged into its base instead
+func (g *generator) work() {
+	candidates := append(append([]*branch{}, g.longLived...), g.features...)
+	b := candidates[g.rng.Intn(len(candidates))]
+	if b.finished() {
+		g.merge(b.base, []*branch{b})
+		return
+	}
+	if b.base == nil && b.head == "" {
+		g.commit(b, "Create "+b.name)
+		return
+	}
+	g.commit(b, fmt.Sprintf("Change %d on %s", b.nbCommit, b.name))
+}
+
+// Merge feature branches into their base, and delete them
+func (g *generator) merge(base *branch, merged []*branch) {
+	heads := make([]string, len(merged))
+	names := make([]string, len(merged))
+	for i, b := range merged {
+		heads[i], names[i] = b.head, "'"+b.name+"'"
+	}
+	g.commit(base, fmt.Sprintf("Merge %s %s into %s", utils.Ternary(len(merged) > 1, "branches", "branch"), strings.Join(names, ", "), base.name), heads...)
+	features := g.features[:0]
+	for _, b := range g.features {
+		if !slices.Contains(merged, b) {
+			features = append(features, b)
+		}
+	}
+	g.features = features
+}
+
+// Start a feature branch off a random long-lived branch, with a first commit
+func (g *generator) startFeature() bool {
+	var bases []*branch
+	for _, b := range g.longLived {
+		if b.head != "" {
+			bases = append(bases, b)
+		}
+	}
+	g.nbFeature++
+	feature := &branch{
+		name:   "feature/" + strconv.Itoa(g.nbFeature),
+		base:   bases[g.rng.Intn(len(bases))],
+		length: 1 + g.rng.Intn(2*max(g.cfg.FeatureLength, 1)-1),
+	}
+	g.features = append(g.features, feature)
+	g.commit(feature, "Start "+feature.name)
+	return true
+}
+
+// Merge at once the finished features of the long-lived branch that has the most of them, false if none has two
+func (g *generator) octopus() bool {
+	var base *branch
+	finished := make(map[*branch][]*branch)
+	for _, b := range g.features {
+		if b.finished() && len(finished[b.base]) < maxParents-1 {
+			finished[b.base] = append(finished[b.base], b)
+			if base == nil || len(finished[b.base]) > len(finished[base]) {
+				base = b.base
+			}
+		}
+	}
+	if base == nil || len(finished[base]) < 2 {
+		return false
+	}
+	g.merge(base, finished[base])
+	return true
+}
+
+// Merge a random long-lived branch into main, or main into it, false if there is nothing new to merge
+func (g *generator) mergeBack() bool {
+	if len(g.longLived) == 1 {
+		return false
+	}
+	main, b := g.longLived[0], g.longLived[1+g.rng.Intn(len(g.longLived)-1)]
+	if b.head == "" {
+		return false
+	}
+	if g.rng.Intn(2) == 0 {
+		if b.merged == b.head {
+			return false
+		}
+		b.merged = b.head
+		g.commit(main, "Merge branch '"+b.name+"' into main", b.head)
+	} else {
+		if b.synced == main.head {
+			return false
+		}
+		b.synced = main.head
+		g.commit(b, "Merge branch 'main' into "+b.name, main.head)
+	}
+	return true
+}
+
+// Copy the last commits of a random feature branch onto another long-lived branch, false if there is none
+func (g *generator) cherryPick() bool {
+	if len(g.features) == 0 {
+		return false
+	}
+	feature := g.features[g.rng.Intn(len(g.features))]
+	target := g.longLived[g.rng.Intn(len(g.longLived))]
+	if target == feature.base || target.head == "" {
+		return false
+	}
+	subjects := feature.subjects[max(0, len(feature.subjects)-maxCherryPicks):]
+	for _, subject := range subjects {
+		if len(g.commits) == g.cfg.Commits {
+			break
+		}
+		g.commit(target, subject+" (cherry picked)")
+	}
+	return true
+}
+
+// Set the refs of the heads of the branches that were not merged
+func (g *generator) setRefs() {
+	byID := make(map[string]map[string]any, len(g.commits))
+	for _, c := range g.commits {
+		byID[c["id"].(string)] = c
+	}
+	for _, b := range append(append([]*branch{}, g.longLived...), g.features...) {
+		if c, ok := byID[b.head]; ok {
+			refs, _ := c["refs"].([]string)
+			c["refs"] = append(refs, b.name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	cfg := DefaultConfig
+	if !reflect.DeepEqual(Generate(cfg), Generate(cfg)) {
+		t.Fatal("same seed, different histories")
+	}
+	other := cfg
+	other.Seed++
+	if reflect.DeepEqual(Generate(cfg), Generate(other)) {
+		t.Fatal("different seeds, same history")
+	}
+}
+
+// Commits are in topological order, newest first, and every parent is in the history
+func TestGenerateTopological(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Commits, cfg.Orphans = 5000, 3
+	commits := Generate(cfg)
+	if len(commits) != cfg.Commits {
+		t.Fatalf("Expected %d commits, got %d", cfg.Commits, len(commits))
+	}
+	rows := make(map[string]int)
+	for row, commit := range commits {
+		id := commit["id"].(string)
+		if _, ok := rows[id]; ok {
+			t.Fatalf("Duplicate id %s", id)
+		}
+		rows[id] = row
+	}
+	for row, commit := range commits {
+		for _, parent := range commit["parents"].([]string) {
+			if parentRow, ok := rows[parent]; !ok || parentRow <= row {
+				t.Fatalf("Commit %s has parent %s above it or missing", commit["id"], parent)
+			}
+		}
+	}
+	if ts0, ts1 := commits[0]["timestamp"].(int64), commits[1]["timestamp"].(int64); ts0 <= ts1 {
+		t.Fatalf("Timestamps are not decreasing: %d, %d", ts0, ts1)
+	}
+}
+
+func TestGenerateShapes(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Commits, cfg.Orphans, cfg.OctopusRate, cfg.CherryPickRate = 2000, 2, 0.05, 0.05
+	var roots, merges, octopuses, cherryPicks int
+	refs := make(map[string]bool)
+	for _, commit := range Generate(cfg) {
+		switch parents := commit["parents"].([]string); {
+		case len(parents) == 0:
+			roots++
+		case len(parents) == 2:
+			merges++
+		case len(parents) > 2:
+			octopuses++
+		}
+		if strings.HasSuffix(commit["subject"].(string), "(cherry picked)") {
+			cherryPicks++
+		}
+		if commitRefs, ok := commit["refs"].([]string); ok {
+			for _, ref := range commitRefs {
+				refs[ref] = true
+			}
+		}
+	}
+	if roots != 1+cfg.Orphans {
+		t.Errorf("Expected %d roots, got %d", 1+cfg.Orphans, roots)
+	}
+	if merges == 0 || octopuses == 0 || cherryPicks == 0 {
+		t.Errorf("Expected merges, octopus merges and cherry-picks, got %d, %d, %d", merges, octopuses, cherryPicks)
+	}
+	for _, ref := range []string{"main", "develop", "release/1", "orphan/1", "orphan/2"} {
+		if !refs[ref] {
+			t.Errorf("Missing ref %s", ref)
+		}
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	if commits := Generate(Config{}); len(commits) != 0 {
+		t.Fatalf("Expected no commits, got %d", len(commits))
+	}
+	cfg := Config{Commits: 1, Orphans: 3}
+	if commits := Generate(cfg); len(commits) != 1 || len(commits[0]["parents"].([]string)) != 0 {
+		t.Fatalf("Expected a root commit, got %v", commits)
+	}
+}
@@ -2,11 +2,15 @@ package git2graph
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
 	log "github.com/sirupsen/logrus"
+	"hash/fnv"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,27 +25,6 @@ type color struct {
 	inUse      bool
 }
 
-// DefaultColors Default colors
-//var DefaultColors = []string{
-//	"#5aa1be",
-//	"#c065b8",
-//	"#c0ab5f",
-//	"#59bc95",
-//	"#7a63be",
-//	"#c0615b",
-//	"#73bb5e",
-//	"#6ee585",
-//	"#7088e8",
-//	"#eb77a3",
-//	"#c2e675",
-//	"#6fdfe9",
-//	"#d87de8",
-//	"#eab774",
-//	"#be82fb",
-//	"#72d7fc",
-//	"#adfb82",
-//}
-
 // DefaultColors Default colors
 var DefaultColors = []string{
 	"#005EBE",
@@ -94,38 +77,150 @@ func (c *CycleColorGen) GetColor(idx int) string {
 	return c.colors[idx%len(c.colors)]
 }
 
+// Lane is the context in which a color is asked for a node or a path
+type Lane struct {
+	ColorIdx int      // Color index given by the layout, reused by other lanes once this one is closed
+	Tip      string   // Id of the node that started the lane, the top of its first-parent chain
+	Refs     []string // Refs pointing to the tip
+	Node     *Node    // Node being colored, or the node a path comes from
+}
+
+// Name returns the branch identity of the lane, its first ref other than HEAD, or the id of its tip
+func (l Lane) Name() string {
+	for _, ref := range l.Refs {
+		if ref != "HEAD" {
+			return ref
+		}
+	}
+	return l.Tip
+}
+
+// ILaneColorGenerator is a color generator that picks colors using the lane context instead of the color index
+type ILaneColorGenerator interface {
+	IColorGenerator
+	GetLaneColor(lane Lane) string
+}
+
+// BranchColorGen is a color generator that hashes the lane name into the palette,
+// so that a branch keeps the same color on every page and after new commits are pushed.
+type BranchColorGen struct {
+	colors []string
+}
+
+// NewBranchColorGen creates a new BranchColorGen
+func NewBranchColorGen(colors []string) *BranchColorGen {
+	return &BranchColorGen{colors: colors}
+}
+
+func (c *BranchColorGen) GetColor(idx int) string {
+	return c.colors[idx%len(c.colors)]
+}
+
+func (c *BranchColorGen) GetLaneColor(lane Lane) string {
+	return hashColor(c.colors, lane.Name())
+}
+
+// AttributeColorGen is a color generator that colors the nodes, and the paths going out of them,
+// by the value of one of their attributes (author email, a custom "team" property...).
+// Values are looked up in an explicit map first, and hashed into the palette otherwise.
+type AttributeColorGen struct {
+	attribute string
+	colors    []string
+	valuesMap map[string]string
+}
+
+// NewAttributeColorGen creates a new AttributeColorGen, valuesMap can be nil
+func NewAttributeColorGen(attribute string, colors []string, valuesMap map[string]string) *AttributeColorGen {
+	return &AttributeColorGen{attribute: attribute, colors: colors, valuesMap: valuesMap}
+}
+
+func (c *AttributeColorGen) GetColor(idx int) string {
+	return c.colors[idx%len(c.colors)]
+}
+
+func (c *AttributeColorGen) GetLaneColor(lane Lane) string {
+	if lane.Node == nil {
+		return c.GetColor(lane.ColorIdx)
+	}
+	value, ok := (*lane.Node)[c.attribute]
+	if !ok {
+		return c.GetColor(lane.ColorIdx)
+	}
+	key := fmt.Sprint(value)
+	if clr, ok := c.valuesMap[key]; ok {
+		return clr
+	}
+	return hashColor(c.colors, key)
+}
+
+// Return the color of the palette the key hashes to
+func hashColor(colors []string, key string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return colors[h.Sum32()%uint32(len(colors))]
+}
+
+// Resolves the colors of nodes and paths with the color generator,
+// building the lane context only if the generator uses it.
+type colorResolver struct {
+	gen     IColorGenerator
+	laneGen ILaneColorGenerator
+	byID    map[string]*Node
+}
+
+func newColorResolver(gen IColorGenerator, inputNodes []*Node) *colorResolver {
+	r := &colorResolver{gen: gen}
+	if laneGen, ok := gen.(ILaneColorGenerator); ok {
+		r.laneGen = laneGen
+		r.byID = make(map[string]*Node, len(inputNodes))
+		for _, node := range inputNodes {
+			r.byID[node.GetID()] = node
+		}
+	}
+	return r
+}
+
+func (r *colorResolver) color(colorIdx int, laneTip, nodeID string) string {
+	if r.laneGen == nil {
+		return r.gen.GetColor(colorIdx)
+	}
+	lane := Lane{ColorIdx: colorIdx, Tip: strings.TrimPrefix(laneTip, priorityLaneIDPrefix), Node: r.byID[nodeID]}
+	if tip, ok := r.byID[lane.Tip]; ok {
+		lane.Refs = tip.GetRefs()
+	}
+	return r.laneGen.GetLaneColor(lane)
+}
+
+func (r *colorResolver) nodeColor(n *internalNode) string {
+	return r.color(n.colorIdx, n.laneTip, n.id)
+}
+func (r *colorResolver) pathColor(p *Path) string { return r.color(p.colorIdx, p.laneTip, p.origin) }
+
 type colorsManager struct {
-	m map[int]*color
+	colors []*color
 }
 
 func newColorsManager() *colorsManager {
-	return &colorsManager{m: make(map[int]*color)}
+	return &colorsManager{colors: make([]*color, 0)}
 }
 
 func (m *colorsManager) getColor(nodeIdx int) (i int) {
-	for {
-		clr, ok := m.m[i]
-		if !ok {
-			clr = &color{}
-			m.m[i] = clr
-		}
-		if nodeIdx >= clr.releaseIdx && !clr.inUse {
+	for ; i < len(m.colors); i++ {
+		if clr := m.colors[i]; nodeIdx >= clr.releaseIdx && !clr.inUse {
 			clr.inUse = true
 			return
 		}
-		i++
 	}
+	m.colors = append(m.colors, &color{inUse: true})
+	return
 }
 
 // we add "2" because we need at least one commit in between two branches to reuse the same color, see test #28
 func (m *colorsManager) releaseColor(colorIdx int, idx int) {
-	for i := range m.m {
-		if i == colorIdx {
-			clr := m.m[i]
-			clr.releaseIdx = idx + 2
-			clr.inUse = false
-			break
-		}
+	if colorIdx < len(m.colors) {
+		clr := m.colors[colorIdx]
+		clr.releaseIdx = idx + 2
+		clr.inUse = false
 	}
 }
 
@@ -166,6 +261,7 @@ type Out struct {
 	FirstSha     string
 	Nodes        []*Node
 	PartialPaths []*PartialPath
+	Orientation  Orientation // Orientation of the layout, for the renderers
 }
 
 // Node is the raw information for a commit
@@ -187,15 +283,22 @@ func (n *Node) GetParents() []string {
 	return parents
 }
 
+// PartialPath is a path passing over the page, its points are like the ones of the nodes paths: [x, y, type]
 type PartialPath struct {
-	Points []IPoint
-	Color  string
+	Points    [][]any
+	Color     string
+	Collapsed bool
 }
 
 // Path defines how to draw a line in between a parent and child nodes
 type Path struct {
-	Points   []IPoint
-	colorIdx int
+	Points    []IPoint
+	colorIdx  int
+	laneTip   string
+	origin    string // Id of the child node the path comes from
+	collapsed bool   // Some points of the path were moved into the overflow lane
+	movedBack bool   // Moved into the column of its parent on the row of the parent, by the merge of an orphan
+	newLane   bool   // Forks into a new column, the commit is the first of the branch of the parent
 }
 
 type PathTest struct {
@@ -217,8 +320,9 @@ func (p *Path) isValid() bool {
 	return p.len() >= 2
 }
 
-func (p *Path) setColor(color int) {
+func (p *Path) setColor(color int, laneTip string) {
 	p.colorIdx = color
+	p.laneTip = laneTip
 }
 
 // Return either or not the path is of type "Fork"
@@ -231,16 +335,43 @@ func (p *Path) isMergeTo() bool {
 	return p.isValid() && p.second().getType().IsMergeTo()
 }
 
+// Return either or not the path goes back to a lower column on the row idx
+func (p *Path) movedBackAt(idx int) bool {
+	for _, point := range p.Points {
+		if point.GetY() == idx && point.getType() == MergeBack {
+			return true
+		}
+	}
+	return false
+}
+
+// PathIndexError is returned when the layout needs more points than a path has,
+// which means that the paths it built are inconsistent
+type PathIndexError struct {
+	Idx    int
+	Length int
+}
+
+func (e *PathIndexError) Error() string {
+	return fmt.Sprintf("layout error: point %d of a path of %d points", e.Idx, e.Length)
+}
+
+// Return the index of the point idx of a path of length points, negative indexes count from the end
 func rotateIdx(idx, length int) int {
 	if idx < 0 {
 		idx = length + idx
-		if idx < 0 {
-			log.Fatal("Weird, need to investigate")
-		}
 	}
 	return idx
 }
 
+// Return a *PathIndexError if the path has fewer than nb points, before the layout looks up its nb last points
+func (p *Path) checkLen(nb int) error {
+	if p.len() < nb {
+		return &PathIndexError{Idx: -nb, Length: p.len()}
+	}
+	return nil
+}
+
 func (p *Path) get(idx int) (out IPoint) {
 	return p.Points[rotateIdx(idx, p.len())]
 }
@@ -266,7 +397,7 @@ func (p *Path) noDupAppend(point *Point) {
 // append a point to a path if it is not a duplicate
 func (p *Path) noDupAppend2(point *Point) {
 	p.noDupAppend(point)
-	for p.last().GetY() == p.thirdToLast().GetY() {
+	for p.len() >= 3 && p.last().GetY() == p.thirdToLast().GetY() {
 		p.removeSecondToLast()
 	}
 }
@@ -362,43 +493,34 @@ func (p *Point) getType() pointType { return p.typ }
 // children are the nodes above the current node
 // A node only ever have at most 2 parents.
 type internalNode struct {
-	initialNode   *Node
-	id            string
-	idx           *int
-	column        int
-	colorIdx      int
-	firstOfBranch bool
-	parents       []*internalNode
-	children      []*internalNode
-	parentsPaths  map[string]*Path
+	initialNode  *Node
+	id           string
+	idx          *int
+	column       int
+	colorIdx     int
+	laneTip      string // Id of the node that started the lane (and got a new color)
+	parents      []*internalNode
+	children     []*internalNode
+	parentsPaths map[string]*Path
 }
 
 func (n *internalNode) setColumn(column int) {
 	n.column = column
 }
 
-func (n *internalNode) setColor(color int) {
+func (n *internalNode) setColor(color int, laneTip string) {
 	n.colorIdx = color
+	n.laneTip = laneTip
 }
 
 func (n *internalNode) isOrphan() bool {
 	return len(n.parents) == 0
 }
 
-// A node is a "firstOfBranch" if there is a path to a parent that needs a new color,
-// and the commit is the first commit in that new branch.
-func (n *internalNode) isFirstOfBranch() bool {
-	return n.firstOfBranch
-}
-
-func (n *internalNode) setFirstOfBranch() {
-	n.firstOfBranch = true
-}
-
 func (n *internalNode) pathTo(parent *internalNode) *Path {
 	parentPath, ok := n.parentsPaths[parent.id]
 	if !ok {
-		parentPath = &Path{}
+		parentPath = &Path{origin: n.id}
 		n.parentsPaths[parent.id] = parentPath
 	}
 	return parentPath
@@ -420,7 +542,8 @@ func (n *internalNode) firstInBranch() bool {
 // A subbranch, is when the child node is in the middle of another branch
 // See test_022.png node #4 (zero-indexed)
 func (n *internalNode) isPathSubBranch(parent *internalNode) bool {
-	return n.pathTo(parent).isFork() && !n.isFirstOfBranch()
+	path := n.pathTo(parent)
+	return path.isFork() && !path.newLane
 }
 
 // Move the node to the left by "nb" columns.
@@ -465,7 +588,8 @@ func (n *internalNode) nbNodesMergingBack(maxX int) (nbNodesMergingBack int) {
 			secondToLastPoint := path.secondToLast()
 			if n.column < secondToLastPoint.getX() && secondToLastPoint.getX() < maxX &&
 				!child.isPathSubBranch(n) &&
-				!path.isMergeTo() {
+				!path.isMergeTo() &&
+				!path.movedBack {
 				nbNodesMergingBack++
 			}
 		}
@@ -490,71 +614,102 @@ func GetInputNodesFromJSON(inputJSON []byte) (nodes []*Node, err error) {
 	if err != nil {
 		return
 	}
-	for _, node := range nodes {
+	for i, node := range nodes {
 		parents := make([]string, 0)
-		nodeParents, ok := (*node)[parentsKey]
+		rawParents, ok := (*node)[parentsKey]
+		if !ok {
+			return nil, fmt.Errorf("malformed json input, node %d missing parents property", i)
+		}
+		nodeParents, ok := rawParents.([]any)
 		if !ok {
-			log.Fatal("malformed json input, node missing parents property")
+			return nil, fmt.Errorf("malformed json input, node %d parents property is not an array", i)
 		}
-		for _, parent := range nodeParents.([]any) {
-			parents = append(parents, parent.(string))
+		for _, nodeParent := range nodeParents {
+			parent, ok := nodeParent.(string)
+			if !ok {
+				return nil, fmt.Errorf("malformed json input, node %d has a non-string parent %v", i, nodeParent)
+			}
+			parents = append(parents, parent)
 		}
 		(*node)[parentsKey] = parents
 	}
 	return
 }
 
+// internalNodeSet is an ordered set of nodes, the most recently added node comes first.
+// Nodes are indexed by pointer and by id (ids are unique among unprocessed nodes), so that Get, Add and Remove are O(1).
 type internalNodeSet struct {
-	a []*internalNode
-	m map[*internalNode]struct{}
+	head *internalNodeSetElem
+	m    map[*internalNode]*internalNodeSetElem
+	ids  map[string]*internalNode
+}
+
+type internalNodeSetElem struct {
+	node       *internalNode
+	prev, next *internalNodeSetElem
 }
 
 func newInternalNodeSet() *internalNodeSet {
 	return &internalNodeSet{
-		a: make([]*internalNode, 0),
-		m: make(map[*internalNode]struct{}),
+		m:   make(map[*internalNode]*internalNodeSetElem),
+		ids: make(map[string]*internalNode),
 	}
 }
 
-func (s *internalNodeSet) Get(key string) *internalNode {
-	for _, n := range s.a {
-		if n.id == key {
-			return n
-		}
+func (s *internalNodeSet) Len() int {
+	return len(s.m)
+}
+
+// All returns the nodes of the set, in order
+func (s *internalNodeSet) All() []*internalNode {
+	out := make([]*internalNode, 0, len(s.m))
+	for e := s.head; e != nil; e = e.next {
+		out = append(out, e.node)
 	}
-	return nil
+	return out
+}
+
+func (s *internalNodeSet) Get(key string) *internalNode {
+	return s.ids[key]
 }
 
 func (s *internalNodeSet) Add(ins []*internalNode) {
 	for _, in := range ins {
 		if _, ok := s.m[in]; !ok {
-			s.a = append([]*internalNode{in}, s.a...)
-			s.m[in] = struct{}{}
+			e := &internalNodeSetElem{node: in, next: s.head}
+			if s.head != nil {
+				s.head.prev = e
+			}
+			s.head = e
+			s.m[in] = e
+			s.ids[in.id] = in
 		}
 	}
 }
 
 func (s *internalNodeSet) Remove(in *internalNode) {
-	for i := len(s.a) - 1; i >= 0; i-- {
-		if s.a[i] == in {
-			s.a = append(s.a[:i], s.a[i+1:]...)
-			break
-		}
+	e, ok := s.m[in]
+	if !ok {
+		return
+	}
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
 	}
 	delete(s.m, in)
+	if s.ids[in.id] == in {
+		delete(s.ids, in.id)
+	}
 }
 
 func ptr[T any](i T) *T {
 	return &i
 }
 
-func ternary[T any](predicate bool, a, b T) T {
-	if predicate {
-		return a
-	}
-	return b
-}
-
 func newNode(id string, idx int) *internalNode {
 	node := &internalNode{}
 	node.id = id
@@ -567,15 +722,20 @@ func newNode(id string, idx int) *internalNode {
 }
 
 type processedNodes struct {
-	m map[string]map[string]bool
+	m     map[string]map[string]bool
+	moved map[string]bool // Nodes already moved left, by the paths of all the merging children
 }
 
 func newProcessedNodes() *processedNodes {
-	return &processedNodes{m: make(map[string]map[string]bool)}
+	return &processedNodes{m: make(map[string]map[string]bool), moved: make(map[string]bool)}
+}
+
+func (p *processedNodes) HasMoved(nodeID string) bool {
+	return p.moved[nodeID]
 }
 
-func (p *processedNodes) HasNode(nodeID string) bool {
-	return p.m[nodeID] != nil
+func (p *processedNodes) SetMoved(nodeID string) {
+	p.moved[nodeID] = true
 }
 
 func (p *processedNodes) HasChild(nodeID, childID string) bool {
@@ -589,29 +749,99 @@ func (p *processedNodes) Set(nodeID, childID string) {
 	p.m[nodeID][childID] = true
 }
 
-func setColumns(inputNodes []*Node, from string, limit int) (nodes []*internalNode, partialPaths []*Path) {
-	origLimit := limit
+func setColumns(ctx context.Context, inputNodes []*Node, opts *Options) (nodes []*internalNode, partialPaths []*Path, err error) {
+	if err = checkLimit(NodesLimit, opts.MaxNodes, len(inputNodes)); err != nil {
+		return
+	}
+	return opts.laneAssigner().assignLanes(ctx, inputNodes, opts)
+}
+
+func (greedyLanes) assignLanes(ctx context.Context, inputNodes []*Node, opts *Options) (nodes []*internalNode, partialPaths []*Path, err error) {
+	from, limit, cache := opts.From, opts.limit(), opts.Cache
+	inputNodes, nbPriorityLanes := withPriorityLanes(inputNodes, opts.PriorityRefs)
+	if from == "" && limit > 0 {
+		limit += nbPriorityLanes
+	}
+	origLimit := opts.limit()
 	colorsMan := newColorsManager()
 	columnMan := newColumnManager()
 	unassignedNodes := make(map[string]*internalNode) // Keep track of nodes for which the row (idx) has not been defined yet
 	tmpRow, followingNodes := -1, newInternalNodeSet()
-	fromIdx := ternary(from == "", 0, -1)
-	for idx, rawNode := range inputNodes {
+	fromIdx := utils.Ternary(from == "", nbPriorityLanes, -1)
+	startIdx := 0
+	if origLimit > 0 {
+		if snapshot := cache.nearest(inputNodes, from); snapshot != nil {
+			startIdx = snapshot.Row
+			tmpRow, unassignedNodes, followingNodes, columnMan, colorsMan = snapshot.restore()
+		}
+	}
+	closedPathPoints := 0 // Points of the paths arriving on the rows laid out, which do not change anymore
+	hasher := newRowsHasher()
+	if cache != nil {
+		for _, rawNode := range inputNodes[:startIdx] {
+			hasher.add(rawNode)
+		}
+	}
+	for idx := startIdx; idx < len(inputNodes); idx++ {
+		rawNode := inputNodes[idx]
 		if limit == 0 {
 			break
 		}
+		if idx%cancelCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if cache != nil {
+			hasher.add(rawNode)
+			if hash, ok := cache.shouldRecord(idx, hasher); ok {
+				cache.record(takeSnapshot(idx, hash, tmpRow, followingNodes, columnMan, colorsMan))
+			}
+		}
 		node := initNode(rawNode, idx, &tmpRow, unassignedNodes, columnMan, colorsMan)
 		nodes = append(nodes, node)
 		updateLimitAndIndex(node, from, &limit, &fromIdx, idx)
 		updateNodeTracking(node, followingNodes)
-		processChildren(node, inputNodes, followingNodes, columnMan, colorsMan)
+		if err = processChildren(node, inputNodes, followingNodes, columnMan, colorsMan); err != nil {
+			return nil, nil, err
+		}
 		processParents(node, inputNodes, columnMan, colorsMan)
+		if err = checkLimit(ColumnsLimit, opts.MaxColumns, columnMan.c+1); err != nil {
+			return nil, nil, err
+		}
+		if opts.MaxPathPoints > 0 {
+			closedPathPoints += pathsPointsTo(node)
+			if err = checkLimit(PathPointsLimit, opts.MaxPathPoints, closedPathPoints+openPathPoints(followingNodes)); err != nil {
+				return nil, nil, err
+			}
+		}
 		if node.id == from {
 			partialPaths = calcPartialPaths(followingNodes)
 		}
 	}
-	finalizeNodes(followingNodes, nodes, partialPaths, fromIdx, origLimit)
-	return sliceResults(nodes, fromIdx, origLimit), partialPaths
+	if err = finalizeNodes(followingNodes, nodes, partialPaths, fromIdx, origLimit, startIdx); err != nil {
+		return nil, nil, err
+	}
+	nodes = removePriorityLanes(sliceResults(nodes, fromIdx-startIdx, origLimit), partialPaths, nbPriorityLanes)
+	return nodes, partialPaths, nil
+}
+
+// Return the number of points of the paths from the children of node to node
+func pathsPointsTo(node *internalNode) (nb int) {
+	for _, child := range node.children {
+		if path, ok := child.parentsPaths[node.id]; ok {
+			nb += path.len()
+		}
+	}
+	return nb
+}
+
+// Return the number of points of the paths going down to the following nodes, which are not laid out yet
+func openPathPoints(followingNodes *internalNodeSet) (nb int) {
+	for e := followingNodes.head; e != nil; e = e.next {
+		nb += pathsPointsTo(e.node)
+	}
+	return nb
 }
 
 func updateLimitAndIndex(node *internalNode, from string, limit, fromIdx *int, idx int) {
@@ -630,10 +860,13 @@ func updateNodeTracking(node *internalNode, followingNodes *internalNodeSet) {
 	followingNodes.Remove(node)
 }
 
-func finalizeNodes(followingNodes *internalNodeSet, nodes []*internalNode, partialPaths []*Path, fromIdx, origLimit int) {
-	setUndefinedRows(followingNodes, len(nodes))
+func finalizeNodes(followingNodes *internalNodeSet, nodes []*internalNode, partialPaths []*Path, fromIdx, origLimit, startIdx int) error {
+	if err := setUndefinedRows(followingNodes, startIdx+len(nodes)); err != nil {
+		return err
+	}
 	cropPartialPaths(partialPaths, fromIdx, origLimit)
 	cropNodesPaths(nodes, fromIdx, origLimit)
+	return nil
 }
 
 func sliceResults(nodes []*internalNode, fromIdx, origLimit int) []*internalNode {
@@ -663,8 +896,11 @@ func cropNodesPaths(nodes []*internalNode, from, limit int) {
 
 // Return the paths that come from outside our page
 func calcPartialPaths(followingNodesWithChildrenBeforeIdx *internalNodeSet) (out []*Path) {
-	for _, n := range followingNodesWithChildrenBeforeIdx.a {
+	for _, n := range followingNodesWithChildrenBeforeIdx.All() {
 		for _, c := range n.children {
+			if c.isPriorityLane() {
+				continue
+			}
 			for _, parent := range c.parents {
 				out = append(out, c.parentsPaths[parent.id])
 			}
@@ -689,7 +925,7 @@ func cropPathAt(path *Path, from, limit int) *Path {
 			points = append(points, p2)
 		}
 	}
-	return &Path{Points: points, colorIdx: path.colorIdx}
+	return &Path{Points: points, colorIdx: path.colorIdx, laneTip: path.laneTip, origin: path.origin}
 }
 
 // Crop a path to `from+limit` height
@@ -704,7 +940,7 @@ func cropPathEndAt(path *Path, from, limit int) *Path {
 		}
 		points = append(points, p2)
 	}
-	return &Path{Points: points, colorIdx: path.colorIdx}
+	return &Path{Points: points, colorIdx: path.colorIdx, laneTip: path.laneTip, origin: path.origin}
 }
 
 func initNode(rawNode *Node, idx int, tmpRow *int, unassignedNodes map[string]*internalNode, columnMan *columnManager, colorsMan *colorsManager) (node *internalNode) {
@@ -733,73 +969,149 @@ func initNode(rawNode *Node, idx int, tmpRow *int, unassignedNodes map[string]*i
 	// Set column if not defined
 	if !node.columnDefined() {
 		node.setColumn(columnMan.next())
-		node.setColor(colorsMan.getColor(*node.idx))
+		node.setColor(colorsMan.getColor(*node.idx), node.id)
 	}
 	return node
 }
 
-func processChildren(node *internalNode, inputNodes []*Node, followingNodesWithChildrenBeforeIdx *internalNodeSet, columnMan *columnManager, colorsMan *colorsManager) {
+func processChildren(node *internalNode, inputNodes []*Node, followingNodesWithChildrenBeforeIdx *internalNodeSet, columnMan *columnManager, colorsMan *colorsManager) error {
 	// Each child that are merging
 	// For each node, we need to check each child.
 	// For each child that is merging back, we need to alter paths that are passing over
 	// and decrement their column.
-	processedNodesInst := newProcessedNodes()
+	var processedNodesInst *processedNodes
+	var candidates []followingNodeChild
+	ownColumnFreed := false // The column of an orphan is freed once, whatever the number of children arriving in it
 	for _, child := range node.children {
 		pathToNode := child.pathTo(node)
+		if err := pathToNode.checkLen(2); err != nil {
+			return err
+		}
 		secondToLastPointX := pathToNode.secondToLast().getX()
+		if pathToNode.movedBack {
+			// The column the path comes from was already freed by the orphan
+			secondToLastPointX = node.column
+		}
 		if node.column < secondToLastPointX || node.isOrphan() {
-			if !child.isPathSubBranch(node) && !pathToNode.isMergeTo() {
+			freesOwnColumn := secondToLastPointX == node.column
+			if !child.isPathSubBranch(node) && !pathToNode.isMergeTo() && !(freesOwnColumn && ownColumnFreed) {
 				columnMan.decr()
 			}
+			ownColumnFreed = ownColumnFreed || freesOwnColumn
 			colorsMan.releaseColor(pathToNode.colorIdx, *node.idx)
 
 			// Insert before the last element
-			if node.column != child.column {
+			if node.column != child.column && !pathToNode.movedBack {
 				pathToNode.noDupInsert(-1, newPoint(secondToLastPointX, node.idx, MergeBack))
 			}
 
-			// Nodes that are following the current node
-			for _, followingNode := range followingNodesWithChildrenBeforeIdx.a {
-				// Following nodes that have a child before the current node
-				for _, followingNodeChild := range followingNode.children {
-					pathToFollowingNode := followingNodeChild.pathTo(followingNode)
-					if *followingNodeChild.idx < *node.idx && !processedNodesInst.HasChild(followingNode.id, followingNodeChild.id) {
-						// Following node child has a path that is higher than the current path being merged
-						targetColumn := pathToFollowingNode.GetHeightAtIdx(*node.idx)
-						if targetColumn > secondToLastPointX {
-							// Remove all nodes, that are next to the last node, that have the same y as the last node
-							for pathToFollowingNode.last().GetY() == pathToFollowingNode.secondToLast().GetY() {
-								pathToFollowingNode.removeSecondToLast()
+			// Following nodes that have a child before the current node.
+			// The list only depends on the current node, so it is computed once for all merging children.
+			if processedNodesInst == nil {
+				processedNodesInst = newProcessedNodes()
+				candidates = followingNodesChildrenBefore(followingNodesWithChildrenBeforeIdx, *node.idx)
+			}
+			for _, candidate := range candidates {
+				followingNode, followingNodeChild := candidate.node, candidate.child
+				pathToFollowingNode := followingNodeChild.pathTo(followingNode)
+				// A path moved back on the row of the node was already moved by the merge of an orphan on the previous row,
+				// which counted the nodes merging into the node unless the node is an orphan too
+				alreadyMoved := !movesPathsOnNextRow(inputNodes, *node.idx) && pathToFollowingNode.movedBackAt(*node.idx)
+				if !processedNodesInst.HasChild(followingNode.id, followingNodeChild.id) && !alreadyMoved {
+					// Following node child has a path that is higher than the current path being merged
+					targetColumn := pathToFollowingNode.GetHeightAtIdx(*node.idx)
+					if targetColumn > secondToLastPointX {
+						// Remove all nodes, that are next to the last node, that have the same y as the last node
+						for {
+							if err := pathToFollowingNode.checkLen(2); err != nil {
+								return err
 							}
-							pathToFollowingNode.removeLast()
-
-							// Calculate nb of merging nodes
-							nbNodesMergingBack := 0
-							nodeForMerge := node
-							if node.isOrphan() && *node.idx+1 < len(inputNodes) {
-								nodeForMerge = followingNodesWithChildrenBeforeIdx.Get(inputNodes[*node.idx+1].GetID())
-								nbNodesMergingBack++
+							if pathToFollowingNode.last().GetY() != pathToFollowingNode.secondToLast().GetY() {
+								break
 							}
-							nbNodesMergingBack += nodeForMerge.nbNodesMergingBack(targetColumn)
-							followingNodeColumn := followingNode.column
-							shouldMoveNode := followingNodeColumn > secondToLastPointX && !processedNodesInst.HasNode(followingNode.id)
-							if shouldMoveNode {
-								followingNodeColumn -= nbNodesMergingBack
+							pathToFollowingNode.removeSecondToLast()
+						}
+						pathToFollowingNode.removeLast()
+
+						// Calculate nb of merging nodes
+						nbNodesMergingBack := 0
+						nbColumnsNodeMoves := 0 // The node moves as much as the path, unless it is in the lane of another child
+						nodeForMerge := node
+						countMerging := true
+						if movesPathsOnNextRow(inputNodes, *node.idx) {
+							next := inputNodes[*node.idx+1]
+							if nodeForMerge = followingNodesWithChildrenBeforeIdx.Get(next.GetID()); nodeForMerge == nil {
+								// The next node has no child, no other path merges back into it
+								nodeForMerge = newNode(next.GetID(), *node.idx+1)
 							}
-							pathPointX := pathToFollowingNode.last().getX()
-							pathToFollowingNode.noDupAppend(newPoint(pathPointX, nodeForMerge.idx, MergeBack))
-							pathToFollowingNode.noDupAppend2(newPoint(pathPointX-nbNodesMergingBack, nodeForMerge.idx, Pipe))
-							pathToFollowingNode.noDupAppend2(newPoint(followingNodeColumn, followingNode.idx, Pipe))
-							if shouldMoveNode {
-								followingNode.moveLeft(nbNodesMergingBack)
+							// Such a next node moves the paths merging into it itself, on the row after it
+							countMerging = !movesPathsOnNextRow(inputNodes, *node.idx+1)
+							if pathToFollowingNode.movedBackAt(*node.idx) {
+								// The path already took the column of a node merging into the orphan, or a column left free.
+								// Paths left of the orphan do not move, the columns left free there stay free.
+								nbNodesMergingBack += freeColumnsBetween(candidates, *node.idx, node.column, targetColumn)
+								nbColumnsNodeMoves += freeColumnsBetween(candidates, *node.idx, node.column, followingNode.column)
+							} else {
+								nbNodesMergingBack += 1 + node.nbNodesMergingBack(targetColumn)
+								nbColumnsNodeMoves += 1 + node.nbNodesMergingBack(min(targetColumn, followingNode.column))
 							}
-							processedNodesInst.Set(followingNode.id, followingNodeChild.id)
 						}
+						if countMerging {
+							nbMergingIntoNext := nodeForMerge.nbNodesMergingBack(targetColumn)
+							nbNodesMergingBack += nbMergingIntoNext
+							nbColumnsNodeMoves += nbMergingIntoNext
+						}
+						followingNodeColumn := followingNode.column
+						shouldMoveNode := followingNodeColumn > secondToLastPointX && !processedNodesInst.HasMoved(followingNode.id)
+						if shouldMoveNode {
+							followingNodeColumn -= nbColumnsNodeMoves
+						}
+						pathPointX := pathToFollowingNode.last().getX()
+						pathToFollowingNode.noDupAppend(newPoint(pathPointX, nodeForMerge.idx, MergeBack))
+						pathToFollowingNode.noDupAppend2(newPoint(pathPointX-nbNodesMergingBack, nodeForMerge.idx, Pipe))
+						pathToFollowingNode.noDupAppend2(newPoint(followingNodeColumn, followingNode.idx, Pipe))
+						if node.isOrphan() && nodeForMerge == followingNode {
+							pathToFollowingNode.movedBack = true
+						}
+						if shouldMoveNode {
+							followingNode.moveLeft(nbColumnsNodeMoves)
+							processedNodesInst.SetMoved(followingNode.id)
+						}
+						processedNodesInst.Set(followingNode.id, followingNodeChild.id)
 					}
 				}
 			}
 		}
 	}
+	return nil
+}
+
+// Return the number of columns from minX to x excluded that no path of the candidates goes down on, from the row idx
+func freeColumnsBetween(candidates []followingNodeChild, idx, minX, x int) int {
+	taken := make(map[int]struct{})
+	for _, candidate := range candidates {
+		if height := candidate.child.pathTo(candidate.node).GetHeightAtIdx(idx); minX <= height && height < x {
+			taken[height] = struct{}{}
+		}
+	}
+	return x - minX - len(taken)
+}
+
+type followingNodeChild struct {
+	node  *internalNode
+	child *internalNode
+}
+
+// Return the (following node, child) pairs for which the child is before idx, in followingNodes order
+func followingNodesChildrenBefore(followingNodes *internalNodeSet, idx int) (out []followingNodeChild) {
+	for e := followingNodes.head; e != nil; e = e.next {
+		for _, child := range e.node.children {
+			if *child.idx < idx {
+				out = append(out, followingNodeChild{node: e.node, child: child})
+			}
+		}
+	}
+	return
 }
 
 func processParents(node *internalNode, inputNodes []*Node, columnMan *columnManager, colorsMan *colorsManager) {
@@ -813,16 +1125,16 @@ func processParent(node *internalNode, parent *internalNode, parentIdx int, inpu
 	nodePathToParent := node.pathTo(parent)
 	nodePathToParent.noDupAppend(newPoint(node.column, node.idx, Pipe))
 	if !parent.columnDefined() {
-		if isFirstParent || node.pathTo(node.parents[0]).isMergeTo() {
+		if isFirstParent || (node.pathTo(node.parents[0]).isMergeTo() && !node.columnTakenByParent(parentIdx)) {
 			parent.setColumn(node.column)
-			parent.setColor(node.colorIdx)
+			parent.setColor(node.colorIdx, node.laneTip)
 		} else {
 			parent.setColumn(columnMan.next())
-			parent.setColor(colorsMan.getColor(*node.idx))
+			parent.setColor(colorsMan.getColor(*node.idx), parent.id)
 			nodePathToParent.noDupAppend(newPoint(parent.column, node.idx, Fork))
-			node.setFirstOfBranch()
+			nodePathToParent.newLane = true
 		}
-		nodePathToParent.setColor(parent.colorIdx)
+		nodePathToParent.setColor(parent.colorIdx, parent.laneTip)
 	} else if node.column < parent.column {
 		if isFirstParent {
 			for _, child := range parent.children {
@@ -834,38 +1146,62 @@ func processParent(node *internalNode, parent *internalNode, parentIdx int, inpu
 				}
 			}
 			parent.setColumn(node.column)
-			parent.setColor(node.colorIdx)
-			nodePathToParent.setColor(node.colorIdx)
+			parent.setColor(node.colorIdx, node.laneTip)
+			nodePathToParent.setColor(node.colorIdx, node.laneTip)
 		} else {
 			nodePathToParent.noDupAppend(newPoint(parent.column, node.idx, Fork))
-			nodePathToParent.setColor(parent.colorIdx)
+			nodePathToParent.setColor(parent.colorIdx, parent.laneTip)
 		}
 	} else if node.column > parent.column {
-		nextNodeID := inputNodes[*node.idx+1].GetID()
+		nextNodeID := "" // The last node has its parents below the last row
+		if *node.idx+1 < len(inputNodes) {
+			nextNodeID = inputNodes[*node.idx+1].GetID()
+		}
 		if isFirstParent && (parent.id != nextNodeID || node.firstInBranch()) {
 			nodePathToParent.noDupAppend(newPoint(node.column, parent.idx, MergeBack))
-			nodePathToParent.setColor(node.colorIdx)
+			nodePathToParent.setColor(node.colorIdx, node.laneTip)
 		} else {
 			nodePathToParent.noDupAppend(newPoint(parent.column, node.idx, MergeTo))
-			nodePathToParent.setColor(parent.colorIdx)
+			nodePathToParent.setColor(parent.colorIdx, parent.laneTip)
 		}
 	} else if node.column == parent.column {
-		parent.setColor(node.colorIdx)
+		parent.setColor(node.colorIdx, node.laneTip)
 	}
 	nodePathToParent.noDupAppend(newPoint(parent.column, parent.idx, Pipe))
 }
 
+// Return either or not the paths passing over the node at row idx are moved on the next row:
+// the column of an orphan is only free below it
+func movesPathsOnNextRow(inputNodes []*Node, idx int) bool {
+	return len(inputNodes[idx].GetParents()) == 0 && idx+1 < len(inputNodes)
+}
+
+// Return either or not one of the first parents of the node continues in its column,
+// when the first parent merges to another column, only one of the other parents can take the column of the node
+func (n *internalNode) columnTakenByParent(parentIdx int) bool {
+	for _, parent := range n.parents[:parentIdx] {
+		if parent.column == n.column {
+			return true
+		}
+	}
+	return false
+}
+
 // Sets idx of all nodes with undefined idx (y coord)
-func setUndefinedRows(followingNodesWithChildrenBeforeIdx *internalNodeSet, lastRowIdx int) {
-	for _, n := range followingNodesWithChildrenBeforeIdx.a {
+func setUndefinedRows(followingNodesWithChildrenBeforeIdx *internalNodeSet, lastRowIdx int) error {
+	for _, n := range followingNodesWithChildrenBeforeIdx.All() {
 		if *n.idx < 0 {
 			for _, c := range n.children {
 				p := c.parentsPaths[n.id]
+				if err := p.checkLen(2); err != nil {
+					return err
+				}
 				p.last().SetX(p.secondToLast().getX())
 			}
 			*n.idx = lastRowIdx
 		}
 	}
+	return nil
 }
 
 func Get(inputNodes []*Node) (*Out, error) {
@@ -878,16 +1214,31 @@ func GetRows(inputNodes []*Node) (*Out, error) {
 
 // GetPaginated gets the necessary information to render the graph for the asked page
 func GetPaginated(inputNodes []*Node, from string, limit int) (*Out, error) {
-	return buildTree(inputNodes, NewCycleColorGen(DefaultColors), from, limit, false)
+	return LayoutContext(context.Background(), inputNodes, &Options{From: from, Limit: limit})
 }
 
 // GetPaginatedRows gets the information to render the graph as rows
 func GetPaginatedRows(inputNodes []*Node, from string, limit int) (*Out, error) {
-	return buildTreeRows(inputNodes, NewCycleColorGen(DefaultColors), from, limit)
+	return LayoutRowsContext(context.Background(), inputNodes, &Options{From: from, Limit: limit})
+}
+
+// GetPaginatedCached is GetPaginated, using (and updating) the lane-state snapshots of cache
+// to avoid laying out the rows above the asked page.
+func GetPaginatedCached(inputNodes []*Node, from string, limit int, cache *LayoutCache) (*Out, error) {
+	return LayoutContext(context.Background(), inputNodes, &Options{From: from, Limit: limit, Cache: cache})
+}
+
+// GetPaginatedRowsCached is GetPaginatedRows, using (and updating) the lane-state snapshots of cache
+func GetPaginatedRowsCached(inputNodes []*Node, from string, limit int, cache *LayoutCache) (*Out, error) {
+	return LayoutRowsContext(context.Background(), inputNodes, &Options{From: from, Limit: limit, Cache: cache})
 }
 
 func buildTreeTest(inputNodes []*Node, colorGen IColorGenerator, from string, limit int) ([]*Node, error) {
-	out, err := buildTree(inputNodes, colorGen, from, limit, true)
+	return buildTreeTestWithOptions(inputNodes, &Options{From: from, Limit: limit, ColorGen: colorGen})
+}
+
+func buildTreeTestWithOptions(inputNodes []*Node, opts *Options) ([]*Node, error) {
+	out, err := buildTree(context.Background(), inputNodes, opts, true)
 	if err != nil {
 		return nil, err
 	}
@@ -896,8 +1247,19 @@ func buildTreeTest(inputNodes []*Node, colorGen IColorGenerator, from string, li
 
 // buildTree given an array of Node, execute the algorithm on it to generate the necessary properties
 // to make it drawable as a graph.
-func buildTree(inputNodes []*Node, colorGen IColorGenerator, from string, limit int, isTest bool) (*Out, error) {
-	nodes, partialPaths := setColumns(inputNodes, from, limit)
+func buildTree(ctx context.Context, inputNodes []*Node, opts *Options, isTest bool) (*Out, error) {
+	inputNodes, opts = foldInput(inputNodes, opts)
+	nodes, partialPaths, err := setColumns(ctx, inputNodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	collapseLanes(nodes, partialPaths, opts.MaxLanes)
+	boundaries := findBoundaries(inputNodes, nodes)
+	if opts.Orientation.reversed() {
+		nodes = flipRows(nodes, partialPaths, len(inputNodes))
+	}
+	positions := newRowPositions(nodes, opts.TimeSpacing)
+	colors := newColorResolver(opts.colorGen(), inputNodes)
 
 	finalStruct := make([]*Node, len(nodes))
 	for nodeIdx, node := range nodes {
@@ -906,39 +1268,60 @@ func buildTree(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 			n := node.parentsPaths[parent.id]
 			path := make([][]any, len(n.Points))
 			for pointIdx, point := range n.Points {
-				path[pointIdx] = []any{point.getX(), point.GetY(), point.getType()}
+				x, y := opts.Orientation.coords(point.getX(), positions.coord(point.GetY()))
+				path[pointIdx] = []any{x, y, point.getType()}
+			}
+			finalParentsPaths[i] = []any{colors.pathColor(n), path}
+			if n.collapsed {
+				finalParentsPaths[i] = append(finalParentsPaths[i].([]any), true)
 			}
-			finalParentsPaths[i] = []any{colorGen.GetColor(n.colorIdx), path}
 		}
 		finalNode := node.initialNode
 		if isTest {
 			(*finalNode)[parentsPathsTestKey] = node.parentsPaths
 		}
-		(*finalNode)[gKey] = []any{node.idx, node.column, colorGen.GetColor(node.colorIdx), finalParentsPaths}
+		(*finalNode)[gKey] = []any{node.idx, node.column, colors.nodeColor(node), finalParentsPaths}
+		setBoundaries(finalNode, boundaries[node])
+		if positions != nil {
+			(*finalNode)[gKey] = append((*finalNode)[gKey].([]any), positions.at(*node.idx))
+		}
 		finalStruct[nodeIdx] = finalNode
 	}
 	finalPP := make([]*PartialPath, 0)
 	for _, p := range partialPaths {
-		finalPP = append(finalPP, &PartialPath{Points: p.Points, Color: colorGen.GetColor(p.colorIdx)})
+		points := make([][]any, len(p.Points))
+		for i, point := range p.Points {
+			x, y := opts.Orientation.coords(point.getX(), positions.coord(point.GetY()))
+			points[i] = []any{x, y, point.getType()}
+		}
+		finalPP = append(finalPP, &PartialPath{Points: points, Color: colors.pathColor(p), Collapsed: p.collapsed})
 	}
 	return &Out{
 		FirstSha:     inputNodes[0].GetID(), // if first sha change, we probably need to re-render the whole tree
 		Nodes:        finalStruct,
 		PartialPaths: finalPP,
+		Orientation:  opts.Orientation,
 	}, nil
 }
 
-func buildTreeRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit int) (*Out, error) {
-	nodes, _ := buildRows(inputNodes, colorGen, from, limit)
+func buildTreeRows(ctx context.Context, inputNodes []*Node, opts *Options) (*Out, error) {
+	nodes, err := buildRows(ctx, inputNodes, opts)
+	if err != nil {
+		return nil, err
+	}
 	finalStruct := make([]*Node, len(nodes))
 	for nodeIdx, node := range nodes {
 		finalNode := node.initialNode
 		(*finalNode)[gKey] = []any{node.x, node.color, node.lines}
+		if node.position != nil {
+			(*finalNode)[gKey] = append((*finalNode)[gKey].([]any), *node.position)
+		}
 		finalStruct[nodeIdx] = finalNode
 	}
 	return &Out{
-		FirstSha: inputNodes[0].GetID(), // if first sha change, we probably need to re-render the whole tree
-		Nodes:    finalStruct,
+		FirstSha:    inputNodes[0].GetID(), // if first sha change, we probably need to re-render the whole tree
+		Nodes:       finalStruct,
+		Orientation: opts.Orientation,
 	}, nil
 }
 
@@ -947,20 +1330,28 @@ type row struct {
 	x           int
 	color       string
 	lines       []rowLine
+	position    *float64 // Position along the timeline, with time spacing only
 }
 
 func (t row) MarshalJSON() ([]byte, error) {
+	if t.position != nil {
+		return json.Marshal([]any{t.x, t.color, t.lines, *t.position})
+	}
 	return json.Marshal([]any{t.x, t.color, t.lines})
 }
 
 type rowLine struct {
-	x1    int
-	x2    int
-	typ   int
-	color string
+	x1        int
+	x2        int
+	typ       int
+	color     string
+	collapsed bool
 }
 
 func (t rowLine) MarshalJSON() ([]byte, error) {
+	if t.collapsed {
+		return json.Marshal([]any{t.x1, t.x2, t.typ, t.color, true})
+	}
 	return json.Marshal([]any{t.x1, t.x2, t.typ, t.color})
 }
 
@@ -972,8 +1363,25 @@ const (
 	MergeBackLine  = 4
 )
 
-func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit int) ([]*row, error) {
-	nodes, partialPaths := setColumns(inputNodes, from, limit)
+// With the LeftToRight orientation, each row is a column of the timeline and the lines go across the lanes (x1, x2 are lanes),
+// the half lines are named after the side of the column they go to
+const (
+	RightHalfLine = BottomHalfLine
+	LeftHalfLine  = TopHalfLine
+)
+
+func buildRows(ctx context.Context, inputNodes []*Node, opts *Options) ([]*row, error) {
+	inputNodes, opts = foldInput(inputNodes, opts)
+	nodes, partialPaths, err := setColumns(ctx, inputNodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	collapseLanes(nodes, partialPaths, opts.MaxLanes)
+	boundaries := findBoundaries(inputNodes, nodes)
+	colors := newColorResolver(opts.colorGen(), inputNodes)
 	offset := *nodes[0].idx
 	out := make([]*row, len(nodes)+1)
 
@@ -983,9 +1391,10 @@ func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 	}
 
 	// Helper function for adding lines to out
+	collapsed := false // Either or not the path being processed goes through the overflow lane
 	addLine := func(yOffset int, x1, x2, lineType int, color string) {
 		if yOffset < len(out) {
-			out[yOffset].lines = append(out[yOffset].lines, rowLine{x1, x2, lineType, color})
+			out[yOffset].lines = append(out[yOffset].lines, rowLine{x1, x2, lineType, color, collapsed})
 		}
 	}
 	addLine1 := func(yOffset int, x1, x2 IPoint, lineType int, color string) {
@@ -997,6 +1406,8 @@ func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 
 	// Process paths and nodes
 	processPath := func(path *Path, offset int, color string, isPartialPath bool) {
+		collapsed = path.collapsed
+		defer func() { collapsed = false }()
 		for i := 1; i < len(path.Points); i++ {
 			p1, p2 := path.Points[i-1], path.Points[i]
 			yOffset1, yOffset2 := p1.GetY()-offset, p2.GetY()-offset
@@ -1040,22 +1451,39 @@ func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 		}
 	}
 
+	// Rows paths are expanded to one point per row, that is what the points limit applies to
+	nbPoints := 0
+	expand := func(path *Path) (*Path, error) {
+		expanded := expandPath(path)
+		nbPoints += expanded.len()
+		return expanded, checkLimit(PathPointsLimit, opts.MaxPathPoints, nbPoints)
+	}
+
 	// Process each partial path
 	for _, path2 := range partialPaths {
 		if len(path2.Points) == 0 {
 			continue
 		}
-		path := expandPath(path2)
-		pathColor := colorGen.GetColor(path.colorIdx)
+		path, err := expand(path2)
+		if err != nil {
+			return nil, err
+		}
+		pathColor := colors.pathColor(path)
 		processPath(path, offset, pathColor, true)
 	}
 
 	// Process nodes and their parent paths
 	for i, node := range nodes {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		t := out[i]
 		t.initialNode = node.initialNode
+		setBoundaries(t.initialNode, boundaries[node])
 		t.x = node.column
-		t.color = colorGen.GetColor(node.colorIdx)
+		t.color = colors.nodeColor(node)
 
 		// draw path arriving at node if the node is the first node of a new page and has children
 		if len(node.children) > 0 {
@@ -1065,17 +1493,33 @@ func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 		}
 
 		for _, parent := range node.parents {
-			parentPath := expandPath(node.parentsPaths[parent.id])
-			pathColor := colorGen.GetColor(parentPath.colorIdx)
+			parentPath, err := expand(node.parentsPaths[parent.id])
+			if err != nil {
+				return nil, err
+			}
+			pathColor := colors.pathColor(parentPath)
 			processPath(parentPath, offset, pathColor, false)
 		}
 	}
 
+	// Left to right rows are the bottom-up rows read as the columns of the timeline, oldest first
+	rows := out[:len(nodes)]
+	if opts.Orientation.reversed() {
+		flipRowsLines(rows)
+		nodes = slices.Clone(nodes)
+		slices.Reverse(nodes)
+	}
+	if positions := newRowPositions(nodes, opts.TimeSpacing); positions != nil {
+		for i := range rows {
+			rows[i].position = &positions.positions[i]
+		}
+	}
+
 	// Sort lines in each row instance
 	isStraight := func(typ int) bool { return typ == BottomHalfLine || typ == TopHalfLine || typ == FullLine }
-	for i := range out {
-		sort.Slice(out[i].lines, func(j, k int) bool {
-			a, b := out[i].lines[j], out[i].lines[k]
+	for i := range rows {
+		sort.Slice(rows[i].lines, func(j, k int) bool {
+			a, b := rows[i].lines[j], rows[i].lines[k]
 			if isStraight(a.typ) {
 				return true
 			}
@@ -1086,12 +1530,54 @@ func buildRows(inputNodes []*Node, colorGen IColorGenerator, from string, limit
 		})
 	}
 
-	return out[:len(nodes)], nil
+	return rows, nil
+}
+
+// Move every column >= maxLanes-1 into the last visible column (the overflow lane).
+// Paths that went through the moved columns are flagged as collapsed,
+// and points made redundant by the move are removed.
+func collapseLanes(nodes []*internalNode, partialPaths []*Path, maxLanes int) {
+	if maxLanes <= 0 {
+		return
+	}
+	overflowLane := maxLanes - 1
+	for _, node := range nodes {
+		node.column = min(node.column, overflowLane)
+		for _, path := range node.parentsPaths {
+			collapsePath(path, overflowLane)
+		}
+	}
+	for _, path := range partialPaths {
+		collapsePath(path, overflowLane)
+	}
+}
+
+func collapsePath(path *Path, overflowLane int) {
+	points := make([]IPoint, 0, path.len())
+	for _, point := range path.Points {
+		if point.getX() > overflowLane {
+			path.collapsed = true
+			point = newPoint(overflowLane, ptr(point.GetY()), point.getType())
+		}
+		if len(points) > 0 {
+			prev := points[len(points)-1]
+			if prev.getX() == point.getX() && prev.GetY() == point.GetY() {
+				// Keep the first point of the path, and the last one which is on its parent
+				if len(points) == 1 {
+					continue
+				}
+				points = points[:len(points)-1]
+			}
+		}
+		points = append(points, point)
+	}
+	path.Points = points
 }
 
+// Return the number of points of all the paths of the nodes and of the partial paths
 // Take a path and make sure there is a point for every row of the path.
 func expandPath(path *Path) *Path {
-	np := &Path{colorIdx: path.colorIdx, Points: []IPoint{path.Points[0]}}
+	np := &Path{colorIdx: path.colorIdx, laneTip: path.laneTip, origin: path.origin, collapsed: path.collapsed, Points: []IPoint{path.Points[0]}}
 	for i := 1; i < len(path.Points); i++ {
 		p1, p2 := path.Points[i-1], path.Points[i]
 		if p2.GetY() > p1.GetY()+1 {
@@ -1137,17 +1623,22 @@ const (
 
 // GetInputNodesFromRepo creates an array of Node from a repository
 func GetInputNodesFromRepo(dir string, order Order, limit int) (nodes []*Node, err error) {
-	return getInputNodesFromRepo(dir, false, order, limit)
-}
-
-// GetInputNodesFromRepoSeq creates an array of Node from a repository. Replace sha by sequential IDs.
-func GetInputNodesFromRepoSeq(dir string, order Order, limit int) (nodes []*Node, err error) {
-	return getInputNodesFromRepo(dir, true, order, limit)
+	args := append([]string{"log", repoLogFormat, "--date=local", "--decorate"}, revisionArgs(order)...)
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-%d", limit+2))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return
+	}
+	return parseRepoLog(string(outBytes)), nil
 }
 
-func getInputNodesFromRepo(dir string, seqIds bool, order Order, limit int) (nodes []*Node, err error) {
-	startOfCommit := "@@@@@@@@@@"
-	args := []string{"log", "--pretty=tformat:" + startOfCommit + "%n%H%n%aN%n%aE%n%at%n%ai%n%P%n%T%n%s%n%d", "--date=local", "--branches", "--remotes", "--decorate"}
+// Return the git log arguments selecting the commits of the graph, in order
+func revisionArgs(order Order) []string {
+	args := []string{"--branches", "--remotes"}
 	switch order {
 	case DateOrder:
 		args = append(args, "--date-order")
@@ -1155,35 +1646,34 @@ func getInputNodesFromRepo(dir string, seqIds bool, order Order, limit int) (nod
 		args = append(args, "--topo-order")
 	case DefaultOrder:
 	}
-	if limit > 0 {
-		args = append(args, fmt.Sprintf("-%d", limit+2))
-	}
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
-	outBytes, err := cmd.Output()
+	return args
+}
+
+// GetInputNodesFromRepoSeq creates an array of Node from a repository. Replace sha by sequential IDs.
+func GetInputNodesFromRepoSeq(dir string, order Order, limit int) (nodes []*Node, err error) {
+	nodes, err = GetInputNodesFromRepo(dir, order, limit)
 	if err != nil {
 		return
 	}
-	outString := string(outBytes)
+	return SequentialIDs(nodes), nil
+}
+
+const (
+	startOfCommit = "@@@@@@@@@@"
+	repoLogFormat = "--pretty=tformat:" + startOfCommit + "%n%H%n%aN%n%aE%n%at%n%ai%n%P%n%T%n%s%n%d"
+)
+
+// Parse the output of git log with the repoLogFormat
+func parseRepoLog(outString string) (nodes []*Node) {
 	lines := strings.Split(outString, "\n") // delim, sha, name, email, date, dateIso, parents, tree, subject, decorate
-	ids := 0
-	shaMap := make(map[string]string)
-	for i := 0; i < len(lines); i += 10 {
+	for i := 0; i+9 < len(lines); i += 10 {
 		if lines[i] != startOfCommit {
 			break
 		}
-		sha := lines[i+1]
 		parents := strings.Split(lines[i+6], " ")
 		parents = deleteEmpty(parents)
-		var id string
-		if seqIds {
-			id = strconv.Itoa(ids)
-			shaMap[sha] = id
-		} else {
-			id = sha
-		}
 		node := &Node{}
-		(*node)[idKey] = id
+		(*node)[idKey] = lines[i+1]
 		(*node)[parentsKey] = parents
 		(*node)[authorNameKey] = lines[i+2]
 		(*node)[authorEmailKey] = lines[i+3]
@@ -1192,17 +1682,36 @@ func getInputNodesFromRepo(dir string, seqIds bool, order Order, limit int) (nod
 		(*node)[subjectKey] = lines[i+8]
 		(*node)[decorateKey] = lines[i+9]
 		nodes = append(nodes, node)
-		ids++
-	}
-	if seqIds {
-		for _, node := range nodes {
-			parents := (*node)[parentsKey].([]string)
-			mappedParents := make([]string, len(parents))
-			for i, parentSha := range parents {
-				mappedParents[i] = shaMap[parentSha]
+	}
+	return nodes
+}
+
+// SequentialIDs replaces the ids of the nodes by sequential ids, "0" for the first node.
+// Parents that are not in nodes get the ids following the last node.
+// Virtual nodes, like the uncommitted changes node, keep their id and do not take a number.
+func SequentialIDs(nodes []*Node) []*Node {
+	idsMap := make(map[string]string, len(nodes))
+	next := 0
+	for _, node := range nodes {
+		if node.IsVirtual() {
+			idsMap[node.GetID()] = node.GetID()
+			continue
+		}
+		idsMap[node.GetID()] = strconv.Itoa(next)
+		next++
+	}
+	for _, node := range nodes {
+		parents := node.GetParents()
+		mappedParents := make([]string, len(parents))
+		for i, parent := range parents {
+			if _, ok := idsMap[parent]; !ok {
+				idsMap[parent] = strconv.Itoa(next)
+				next++
 			}
-			(*node)[parentsKey] = mappedParents
+			mappedParents[i] = idsMap[parent]
 		}
+		(*node)[idKey] = idsMap[node.GetID()]
+		(*node)[parentsKey] = mappedParents
 	}
-	return
+	return nodes
 }
@@ -1,8 +1,13 @@
 package git2graph
 
 import (
+	"context"
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/alaingilbert/git2graph/git2graph/gen"
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
 )
 
 func validateColumns(t *testing.T, expectedColumns []int, data []*Node) {
@@ -108,6 +113,72 @@ func TestNotEnoughColors(t *testing.T) {
 	}
 }
 
+func nodeColors(out *Out) map[string]string {
+	colors := make(map[string]string)
+	for _, node := range out.Nodes {
+		colors[node.GetID()] = (*node)[gKey].([]any)[2].(string)
+	}
+	return colors
+}
+
+func TestBranchColorGenStable(t *testing.T) {
+	newInput := func() []*Node {
+		return []*Node{
+			{"id": "2", "parents": []string{"4"}, "refs": []any{"main"}},
+			{"id": "3", "parents": []string{"5"}, "refs": []any{"feature"}},
+			{"id": "4", "parents": []string{"6"}},
+			{"id": "5", "parents": []string{"6"}},
+			{"id": "6", "parents": []string{}},
+		}
+	}
+	colorGen := NewBranchColorGen(DefaultColors)
+	opts := &Options{ColorGen: colorGen, PriorityRefs: []string{"main"}}
+	full, _ := LayoutContext(context.Background(), newInput(), opts)
+	expected := nodeColors(full)
+	assertEq(t, colorGen.GetLaneColor(Lane{Refs: []string{"main"}}), expected["2"])
+	assertEq(t, colorGen.GetLaneColor(Lane{Refs: []string{"feature"}}), expected["3"])
+
+	// Same colors on another page
+	page, _ := LayoutContext(context.Background(), newInput(), &Options{From: "3", Limit: 2, ColorGen: colorGen, PriorityRefs: opts.PriorityRefs})
+	for id, color := range nodeColors(page) {
+		assertEq(t, expected[id], color)
+	}
+
+	// Same colors once new commits are pushed on top of the feature branch
+	inputNodes := newInput()
+	(*inputNodes[1])[refsKey] = []any{}
+	inputNodes = append([]*Node{{"id": "1", "parents": []string{"3"}, "refs": []any{"feature"}}}, inputNodes...)
+	pushed, _ := LayoutContext(context.Background(), inputNodes, opts)
+	for id, color := range nodeColors(pushed) {
+		if id != "1" {
+			assertEq(t, expected[id], color)
+		}
+	}
+}
+
+func TestAttributeColorGen(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}, "email": "alice@example.com"},
+		{"id": "2", "parents": []string{"4"}, "email": "bob@example.com"},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}, "email": "alice@example.com"},
+	}
+	colorGen := NewAttributeColorGen(authorEmailKey, DefaultColors, map[string]string{"alice@example.com": "alice"})
+	out, _ := LayoutContext(context.Background(), inputNodes, &Options{ColorGen: colorGen})
+	colors := nodeColors(out)
+	assertEq(t, "alice", colors["1"])
+	assertEq(t, hashColor(DefaultColors, "bob@example.com"), colors["2"])
+	assertEq(t, colorGen.GetColor(1), colors["3"])
+	assertEq(t, "alice", colors["4"])
+	// Paths are colored by the node they come from
+	for _, path := range (*out.Nodes[0])[gKey].([]any)[3].([]any) {
+		assertEq(t, "alice", path.([]any)[0])
+	}
+	for _, path := range (*out.Nodes[1])[gKey].([]any)[3].([]any) {
+		assertEq(t, colors["2"], path.([]any)[0])
+	}
+}
+
 func TestGetInputNodesFromJson(t *testing.T) {
 	json := `[{"id": "1", "parents": ["2"]}, {"id": "2", "parents": ["3"]}, {"id": "3", "parents": []}]`
 	inputNodes, _ := GetInputNodesFromJSON([]byte(json))
@@ -135,105 +206,49 @@ func TestGetInputNodesFromJsonWithBadJson(t *testing.T) {
 	}
 }
 
-// 1
-// |
-// 2
-// |
-// 3
-func Test1(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"2"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{}},
+func TestGetInputNodesFromJsonWithMalformedParents(t *testing.T) {
+	tests := []struct {
+		json string
+		err  string
+	}{
+		{`[{"id": "1", "parents": ["2"]}, {"id": "2"}]`, "malformed json input, node 1 missing parents property"},
+		{`[{"id": "1", "parents": "2"}]`, "malformed json input, node 0 parents property is not an array"},
+		{`[{"id": "1", "parents": [2]}]`, "malformed json input, node 0 has a non-string parent 2"},
 	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"2": {[]*PointTest{{0, 0, 0}, {0, 1, 0}}, 0},
-		},
-		{
-			"3": {[]*PointTest{{0, 1, 0}, {0, 2, 0}}, 0},
-		},
+	for _, tt := range tests {
+		_, err := GetInputNodesFromJSON([]byte(tt.json))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("expected %q, got %v", tt.err, err)
+		}
 	}
+}
 
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
+func TestSequentialIDs(t *testing.T) {
+	nodes := SequentialIDs([]*Node{
+		{idKey: "b", parentsKey: []string{"a", "x"}},
+		{idKey: "a", parentsKey: []string{"y"}},
+	})
+	assertEq(t, "0", nodes[0].GetID())
+	assertEq(t, "1", nodes[0].GetParents()[0])
+	assertEq(t, "2", nodes[0].GetParents()[1])
+	assertEq(t, "1", nodes[1].GetID())
+	assertEq(t, "3", nodes[1].GetParents()[0])
 }
 
+// 1
+// |
+// 2
+// |
+// 3
 // 1
 // | 2
 // |/
 // 3
-func Test2(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {1, 2, 1}, {0, 2, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
 // |/
 // 3
-func Test3(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {1, 2, 1}, {0, 2, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -244,44 +259,6 @@ func Test3(t *testing.T) {
 // | |/
 // |/
 // 5
-func Test4(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"5", "4"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 2, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{0, 2, 0}, {0, 4, 0}}, 0},
-			"4": {[]*PointTest{{0, 2, 0}, {2, 2, 2}, {2, 3, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{2, 3, 0}, {2, 4, 1}, {0, 4, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // | 2
 // | | 3
@@ -291,46 +268,6 @@ func Test4(t *testing.T) {
 // | 5
 // |/
 // 6
-func Test5(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"4"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 3, 1}, {0, 3, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{2, 2, 0}, {2, 3, 1}, {0, 3, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{0, 3, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 4, 0}, {1, 5, 1}, {0, 5, 0}}, 3},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -339,44 +276,6 @@ func Test5(t *testing.T) {
 // | 4
 // |/
 // 5
-func Test6(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"3", "4"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {0, 1, 3}, {0, 2, 0}}, 0},
-			"4": {[]*PointTest{{1, 1, 0}, {1, 3, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{0, 2, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 3, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -387,48 +286,6 @@ func Test6(t *testing.T) {
 // 5 |
 // |/
 // 6
-func Test7(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"4", "5"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 1, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 3, 0}}, 1},
-			"5": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 4, 1}, {0, 4, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{0, 2, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 3, 0}, {1, 5, 1}, {0, 5, 0}}, 1},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -440,48 +297,6 @@ func Test7(t *testing.T) {
 // | 5
 // |/
 // 6
-func Test8(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"4"}},
-		{"id": "3", "parents": []string{"4", "5"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 3, 1}, {0, 3, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{0, 2, 0}, {0, 3, 0}}, 0},
-			"5": {[]*PointTest{{0, 2, 0}, {2, 2, 2}, {2, 3, 1}, {1, 3, 0}, {1, 4, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{0, 3, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 4, 0}, {1, 5, 1}, {0, 5, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -499,57 +314,6 @@ func Test8(t *testing.T) {
 // | |/
 // |/
 // 8
-func Test9(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3", "2"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"4", "7"}},
-		{"id": "4", "parents": []string{"5", "6"}},
-		{"id": "5", "parents": []string{"8"}},
-		{"id": "6", "parents": []string{"8"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 0, 0, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{0, 2, 0}, {0, 3, 0}}, 0},
-			"7": {[]*PointTest{{0, 2, 0}, {2, 2, 2}, {2, 4, 1}, {1, 4, 0}, {1, 6, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{0, 3, 0}, {0, 4, 0}}, 0},
-			"6": {[]*PointTest{{0, 3, 0}, {3, 3, 2}, {3, 4, 1}, {2, 4, 0}, {2, 5, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{0, 4, 0}, {0, 7, 0}}, 0},
-		},
-		{
-			"8": {[]*PointTest{{2, 5, 0}, {2, 7, 1}, {0, 7, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -567,58 +331,6 @@ func Test9(t *testing.T) {
 // | 7
 // |/
 // 8
-func Test10(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"4", "2"}},
-		{"id": "2", "parents": []string{"5", "3"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"8", "6"}},
-		{"id": "5", "parents": []string{"7", "6"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 1, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-			"3": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 2, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {1, 4, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{0, 3, 0}, {0, 7, 0}}, 0},
-			"6": {[]*PointTest{{0, 3, 0}, {3, 3, 2}, {3, 4, 1}, {2, 4, 0}, {2, 5, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{1, 4, 0}, {2, 4, 2}, {2, 5, 0}}, 3},
-			"7": {[]*PointTest{{1, 4, 0}, {1, 6, 0}}, 1},
-		},
-		{
-			"7": {[]*PointTest{{2, 5, 0}, {2, 6, 1}, {1, 6, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |-2
 // 3 |
@@ -626,48 +338,6 @@ func Test10(t *testing.T) {
 // 5 |
 // |/
 // 6
-func Test11(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"3", "4"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"5", "6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 1, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {0, 1, 3}, {0, 2, 0}}, 0},
-			"4": {[]*PointTest{{1, 1, 0}, {1, 3, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{0, 2, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 3, 0}, {0, 3, 3}, {0, 4, 0}}, 0},
-			"6": {[]*PointTest{{1, 3, 0}, {1, 5, 1}, {0, 5, 0}}, 1},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |-2
 // 3 |
@@ -680,35 +350,6 @@ func Test11(t *testing.T) {
 // | 6
 // |/
 // 7
-func Test12(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"3", "6"}},
-		{"id": "3", "parents": []string{"5", "4"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"7"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 2, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // |\
 // | 2
@@ -728,39 +369,6 @@ func Test12(t *testing.T) {
 // | |/
 // |/
 // 10
-func Test13(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"4", "2"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{"5", "9"}},
-		{"id": "4", "parents": []string{"7", "5"}},
-		{"id": "5", "parents": []string{"6", "8"}},
-		{"id": "6", "parents": []string{"10"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{"10"}},
-		{"id": "9", "parents": []string{"10"}},
-		{"id": "10", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 1, 0, 1, 1, 0, 0, 2, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-			"2": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // | 2
 // 3 |\
@@ -776,51 +384,6 @@ func Test13(t *testing.T) {
 // | |/
 // |/
 // 8
-func Test14(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"5", "4"}},
-		{"id": "3", "parents": []string{"6", "4"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"8", "7"}},
-		{"id": "6", "parents": []string{"8", "7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 2, 1, 0, 2, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-			"4": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 3, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{0, 2, 0}, {0, 5, 0}}, 0},
-			"4": {[]*PointTest{{0, 2, 0}, {2, 2, 2}, {2, 3, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{2, 3, 0}, {2, 4, 1}, {1, 4, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{1, 4, 0}, {1, 7, 1}, {0, 7, 0}}, 1},
-			"7": {[]*PointTest{{1, 4, 0}, {2, 4, 2}, {2, 6, 0}}, 3},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // | 2
 // 3 |\
@@ -838,55 +401,6 @@ func Test14(t *testing.T) {
 // | |/
 // |/
 // 8
-func Test15(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"6", "4"}},
-		{"id": "3", "parents": []string{"5", "4"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"8", "7"}},
-		{"id": "6", "parents": []string{"8", "7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 2, 0, 1, 2, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-			"4": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 3, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{0, 2, 0}, {0, 4, 0}}, 0},
-			"4": {[]*PointTest{{0, 2, 0}, {2, 2, 2}, {2, 3, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{2, 3, 0}, {2, 5, 1}, {1, 5, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{0, 4, 0}, {0, 7, 0}}, 0},
-			"7": {[]*PointTest{{0, 4, 0}, {3, 4, 2}, {3, 5, 1}, {2, 5, 0}, {2, 6, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{1, 5, 0}, {1, 7, 1}, {0, 7, 0}}, 1},
-			"7": {[]*PointTest{{1, 5, 0}, {2, 5, 2}, {2, 6, 0}}, 3},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
 // 1
 // | 2
 // | | 3
@@ -896,1544 +410,156 @@ func Test15(t *testing.T) {
 // | 6
 // |/
 // 7
-func Test16(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"6"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"7"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{}},
+// Test41 test the date-order bug where parent defined before node ends up with an infinite branch going down
+func assertEq(t *testing.T, expected, actual any) {
+	t.Helper()
+	if actual != expected {
+		t.Logf("Expected: %d, Actual: %d", expected, actual)
+		t.Fail()
 	}
+}
 
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {0, 4, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{3, 3, 0}, {3, 4, 1}, {2, 4, 0}, {2, 5, 1}, {1, 5, 0}}, 3},
-		},
-		{
-			"7": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"7": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 1},
-		},
+func convertPoints[P IPoint](arr []P) (out []IPoint) {
+	for _, v := range arr {
+		out = append(out, v)
 	}
+	return
+}
 
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
+func TestPathHeight1(t *testing.T) {
+	path := &Path{Points: convertPoints([]*PointTest{
+		{0, 2, 0},
+		{3, 2, 2},
+		{3, 9, 1},
+		{2, 9, 0},
+		{2, 11, 1},
+		{1, 11, 0},
+	})}
+	assertEq(t, -1, path.GetHeightAtIdx(1))
+	assertEq(t, 3, path.GetHeightAtIdx(2))
+	assertEq(t, 3, path.GetHeightAtIdx(3))
+	assertEq(t, 2, path.GetHeightAtIdx(9))
+	assertEq(t, 2, path.GetHeightAtIdx(10))
+	assertEq(t, 1, path.GetHeightAtIdx(11))
 }
 
-func Test17(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"4"}},
-		{"id": "3", "parents": []string{"6"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
+func TestPathIndexError(t *testing.T) {
+	path := &Path{Points: convertPoints([]*PointTest{{0, 2, 0}, {0, 3, 0}})}
+	assertEq(t, 3, path.get(-1).GetY())
+	assertEq(t, nil, path.checkLen(2))
+	pathErr, ok := path.checkLen(3).(*PathIndexError)
+	assertEq(t, true, ok)
+	assertEq(t, -3, pathErr.Idx)
+	assertEq(t, 2, pathErr.Length)
+}
+
+func BenchmarkTest1(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		inputNodes := []*Node{
+			{"id": "0", "parents": []string{"4"}},
+			{"id": "1", "parents": []string{"15"}},
+			{"id": "2", "parents": []string{"14"}},
+			{"id": "3", "parents": []string{"22"}},
+			{"id": "4", "parents": []string{"5", "10"}},
+			{"id": "5", "parents": []string{"6", "7"}},
+			{"id": "6", "parents": []string{"16", "8"}},
+			{"id": "7", "parents": []string{"9"}},
+			{"id": "8", "parents": []string{"11"}},
+			{"id": "9", "parents": []string{"16"}},
+			{"id": "10", "parents": []string{"18"}},
+			{"id": "11", "parents": []string{"12"}},
+			{"id": "12", "parents": []string{"13", "16"}},
+			{"id": "13", "parents": []string{"21"}},
+			{"id": "14", "parents": []string{"18"}},
+			{"id": "15", "parents": []string{"23"}},
+			{"id": "16", "parents": []string{"18", "17"}},
+			{"id": "17", "parents": []string{"18"}},
+			{"id": "18", "parents": []string{"20", "19"}},
+			{"id": "19", "parents": []string{"20"}},
+			{"id": "20", "parents": []string{"24"}},
+			{"id": "21", "parents": []string{"22"}},
+			{"id": "22", "parents": []string{"23"}},
+			{"id": "23", "parents": []string{"24"}},
+			{"id": "24", "parents": []string{}},
+		}
+		if _, err := buildTreeTest(inputNodes, customColors, "", -1); err != nil {
+			b.Logf("Failed to build tree")
+		}
 	}
+}
 
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
+// Generate a main branch of n commits, where roughly one commit out of five merges
+// a feature branch of four commits forked two commits lower.
+func generateLinearNodes(n int) []*Node {
+	nodes := make([]*Node, 0, n+5)
+	main := func(i int) string { return "m" + strconv.Itoa(i) }
+	for m := 0; len(nodes) < n; m++ {
+		if m%5 != 2 {
+			nodes = append(nodes, &Node{"id": main(m), "parents": []string{main(m + 1)}})
+			continue
+		}
+		feature := func(k int) string { return "f" + strconv.Itoa(m) + "_" + strconv.Itoa(k) }
+		nodes = append(nodes, &Node{"id": main(m), "parents": []string{main(m + 1), feature(0)}})
+		nodes = append(nodes, &Node{"id": main(m + 1), "parents": []string{main(m + 2)}})
+		for k := 0; k < 3; k++ {
+			nodes = append(nodes, &Node{"id": feature(k), "parents": []string{feature(k + 1)}})
+		}
+		nodes = append(nodes, &Node{"id": feature(3), "parents": []string{main(m + 2)}})
+		m++
+	}
+	(*nodes[len(nodes)-1])[parentsKey] = []string{}
+	return nodes
+}
 
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 0, 0}
+// Generate w long-lived branches committed round-robin, all forked from the same root commit.
+func generateWideNodes(n, w int) []*Node {
+	nodes := make([]*Node, 0, n)
+	commit := func(b, k int) string { return "b" + strconv.Itoa(b) + "_" + strconv.Itoa(k) }
+	for i := 0; i < n-1; i++ {
+		b, k := i%w, i/w
+		parent := utils.Ternary(i+w >= n-1, "root", commit(b, k+1))
+		nodes = append(nodes, &Node{"id": commit(b, k), "parents": []string{parent}})
+	}
+	return append(nodes, &Node{"id": "root", "parents": []string{}})
+}
 
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {0, 4, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{3, 3, 0}, {3, 4, 1}, {1, 4, 0}, {1, 6, 1}, {0, 6, 0}}, 3},
-		},
-		{
-			"5": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{0, 5, 0}, {0, 6, 0}}, 0},
-		},
+// Generate a synthetic history with the gen package
+func generateNodes(cfg gen.Config) []*Node {
+	commits := gen.Generate(cfg)
+	nodes := make([]*Node, len(commits))
+	for i, commit := range commits {
+		node := Node(commit)
+		nodes[i] = &node
 	}
+	return nodes
+}
 
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test18(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"5"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {1, 4, 0}, {1, 5, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{3, 3, 0}, {3, 4, 1}, {2, 4, 0}, {2, 5, 1}, {1, 5, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test19(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"9"}},
-		{"id": "3", "parents": []string{"7"}},
-		{"id": "4", "parents": []string{"11", "6"}},
-		{"id": "5", "parents": []string{"8", "6"}},
-		{"id": "6", "parents": []string{"11"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{"10"}},
-		{"id": "9", "parents": []string{"10"}},
-		{"id": "10", "parents": []string{"12"}},
-		{"id": "11", "parents": []string{"12"}},
-		{"id": "12", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 1, 0, 4, 3, 0, 2, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-		},
-		{
-			"9": {[]*PointTest{{2, 2, 0}, {2, 9, 0}}, 2},
-		},
-		{
-			"7": {[]*PointTest{{3, 3, 0}, {3, 7, 0}}, 3},
-		},
-		{
-			"11": {[]*PointTest{{1, 4, 0}, {1, 11, 0}}, 1},
-			"6":  {[]*PointTest{{1, 4, 0}, {4, 4, 2}, {4, 6, 0}}, 4},
-		},
-		{
-			"8": {[]*PointTest{{0, 5, 0}, {0, 8, 0}}, 0},
-			"6": {[]*PointTest{{0, 5, 0}, {4, 5, 2}, {4, 6, 0}}, 4},
-		},
-		{
-			"11": {[]*PointTest{{4, 6, 0}, {4, 8, 1}, {3, 8, 0}, {3, 10, 1}, {2, 10, 0}, {2, 11, 1}, {1, 11, 0}}, 4},
-		},
-		{
-			"8": {[]*PointTest{{3, 7, 0}, {3, 8, 1}, {0, 8, 0}}, 3},
-		},
-		{
-			"10": {[]*PointTest{{0, 8, 0}, {0, 10, 0}}, 0},
-		},
-		{
-			"10": {[]*PointTest{{2, 9, 0}, {2, 10, 1}, {0, 10, 0}}, 2},
-		},
-		{
-			"12": {[]*PointTest{{0, 10, 0}, {0, 12, 0}}, 0},
-		},
-		{
-			"12": {[]*PointTest{{1, 11, 0}, {1, 12, 1}, {0, 12, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test20(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {1, 4, 0}, {1, 5, 1}, {0, 5, 0}}, 2},
-		},
-		{
-			"4": {[]*PointTest{{3, 3, 0}, {3, 4, 1}, {0, 4, 0}}, 3},
-		},
-		{
-			"5": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test21(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"6", "5"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"7"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 1, 0, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {1, 3, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 5, 1}, {0, 5, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{1, 3, 0}, {1, 6, 0}}, 1},
-			"5": {[]*PointTest{{1, 3, 0}, {2, 3, 2}, {2, 5, 1}, {0, 5, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"7": {[]*PointTest{{0, 5, 0}, {0, 7, 0}}, 0},
-		},
-		{
-			"7": {[]*PointTest{{1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test22(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"6"}},
-		{"id": "3", "parents": []string{"7"}},
-		{"id": "4", "parents": []string{"7", "6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{"8"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 1, 0, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-		},
-		{
-			"6": {[]*PointTest{{2, 2, 0}, {2, 6, 1}, {0, 6, 0}}, 2},
-		},
-		{
-			"7": {[]*PointTest{{3, 3, 0}, {3, 6, 1}, {2, 6, 0}, {2, 7, 1}, {1, 7, 0}}, 3},
-		},
-		{
-			"7": {[]*PointTest{{1, 4, 0}, {1, 7, 0}}, 1},
-			"6": {[]*PointTest{{1, 4, 0}, {2, 4, 2}, {2, 6, 1}, {0, 6, 0}}, 2},
-		},
-		{
-			"6": {[]*PointTest{{0, 5, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"8": {[]*PointTest{{0, 6, 0}, {0, 8, 0}}, 0},
-		},
-		{
-			"8": {[]*PointTest{{1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test23(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"4"}},
-		{"id": "3", "parents": []string{"7"}},
-		{"id": "4", "parents": []string{"6", "5"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{"8"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 2, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {0, 4, 0}}, 2},
-		},
-		{
-			"7": {[]*PointTest{{3, 3, 0}, {3, 4, 1}, {1, 4, 0}, {1, 7, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-			"5": {[]*PointTest{{0, 4, 0}, {2, 4, 2}, {2, 5, 0}}, 4},
-		},
-		{
-			"6": {[]*PointTest{{2, 5, 0}, {2, 6, 1}, {0, 6, 0}}, 4},
-		},
-		{
-			"8": {[]*PointTest{{0, 6, 0}, {0, 8, 0}}, 0},
-		},
-		{
-			"8": {[]*PointTest{{1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 3},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test24(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"3"}},
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"9"}},
-		{"id": "3", "parents": []string{"7", "6"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{"10", "8"}},
-		{"id": "7", "parents": []string{"11", "8"}},
-		{"id": "8", "parents": []string{"9"}},
-		{"id": "9", "parents": []string{"10"}},
-		{"id": "10", "parents": []string{"11"}},
-		{"id": "11", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 4, 1, 1, 0, 3, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-		},
-		{
-			"9": {[]*PointTest{{2, 2, 0}, {2, 9, 0}}, 2},
-		},
-		{
-			"7": {[]*PointTest{{0, 3, 0}, {0, 7, 0}}, 0},
-			"6": {[]*PointTest{{0, 3, 0}, {3, 3, 2}, {3, 6, 1}, {1, 6, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{4, 4, 0}, {4, 6, 1}, {1, 6, 0}}, 4},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 0}}, 1},
-		},
-		{
-			"10": {[]*PointTest{{1, 6, 0}, {1, 10, 0}}, 1},
-			"8":  {[]*PointTest{{1, 6, 0}, {3, 6, 2}, {3, 8, 0}}, 5},
-		},
-		{
-			"11": {[]*PointTest{{0, 7, 0}, {0, 11, 0}}, 0},
-			"8":  {[]*PointTest{{0, 7, 0}, {3, 7, 2}, {3, 8, 0}}, 5},
-		},
-		{
-			"9": {[]*PointTest{{3, 8, 0}, {3, 9, 1}, {2, 9, 0}}, 5},
-		},
-		{
-			"10": {[]*PointTest{{2, 9, 0}, {2, 10, 1}, {1, 10, 0}}, 2},
-		},
-		{
-			"11": {[]*PointTest{{1, 10, 0}, {1, 11, 1}, {0, 11, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test25(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"3"}},
-		{"id": "2", "parents": []string{"4"}},
-		{"id": "3", "parents": []string{"9", "7"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"8", "7"}},
-		{"id": "6", "parents": []string{"9", "7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{"12", "9"}},
-		{"id": "9", "parents": []string{"11", "10"}},
-		{"id": "10", "parents": []string{"11"}},
-		{"id": "11", "parents": []string{"12"}},
-		{"id": "12", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 1, 2, 0, 2, 3, 0, 1, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {1, 3, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{2, 2, 0}, {2, 4, 0}}, 2},
-		},
-		{
-			"9": {[]*PointTest{{1, 3, 0}, {1, 9, 0}}, 1},
-			"7": {[]*PointTest{{1, 3, 0}, {3, 3, 2}, {3, 7, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{2, 4, 0}, {2, 6, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{0, 5, 0}, {0, 8, 0}}, 0},
-			"7": {[]*PointTest{{0, 5, 0}, {3, 5, 2}, {3, 7, 0}}, 3},
-		},
-		{
-			"9": {[]*PointTest{{2, 6, 0}, {2, 9, 1}, {1, 9, 0}}, 2},
-			"7": {[]*PointTest{{2, 6, 0}, {3, 6, 2}, {3, 7, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{3, 7, 0}, {3, 8, 1}, {0, 8, 0}}, 3},
-		},
-		{
-			"12": {[]*PointTest{{0, 8, 0}, {0, 12, 0}}, 0},
-			"9":  {[]*PointTest{{0, 8, 0}, {1, 8, 2}, {1, 9, 0}}, 1},
-		},
-		{
-			"11": {[]*PointTest{{1, 9, 0}, {1, 11, 0}}, 1},
-			"10": {[]*PointTest{{1, 9, 0}, {2, 9, 2}, {2, 10, 0}}, 4},
-		},
-		{
-			"11": {[]*PointTest{{2, 10, 0}, {2, 11, 1}, {1, 11, 0}}, 4},
-		},
-		{
-			"12": {[]*PointTest{{1, 11, 0}, {1, 12, 1}, {0, 12, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test26(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"3"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"8", "5"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"7", "6"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 1, 1, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 5, 1}, {1, 5, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{0, 3, 0}, {0, 8, 0}}, 0},
-			"5": {[]*PointTest{{0, 3, 0}, {2, 3, 2}, {2, 5, 1}, {1, 5, 0}}, 2},
-		},
-		{
-			"5": {[]*PointTest{{1, 4, 0}, {1, 5, 0}}, 1},
-		},
-		{
-			"7": {[]*PointTest{{1, 5, 0}, {1, 7, 0}}, 1},
-			"6": {[]*PointTest{{1, 5, 0}, {2, 5, 2}, {2, 6, 0}}, 3},
-		},
-		{
-			"7": {[]*PointTest{{2, 6, 0}, {2, 7, 1}, {1, 7, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test27(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"7"}},
-		{"id": "3", "parents": []string{"11"}},
-		{"id": "4", "parents": []string{"15", "6"}},
-		{"id": "5", "parents": []string{"8", "6"}},
-		{"id": "6", "parents": []string{"15"}},
-		{"id": "7", "parents": []string{"12"}},
-		{"id": "8", "parents": []string{"9", "13"}},
-		{"id": "9", "parents": []string{"14", "10"}},
-		{"id": "10", "parents": []string{"14"}},
-		{"id": "11", "parents": []string{"14"}},
-		{"id": "12", "parents": []string{"14"}},
-		{"id": "13", "parents": []string{"14"}},
-		{"id": "14", "parents": []string{"16"}},
-		{"id": "15", "parents": []string{"17"}},
-		{"id": "16", "parents": []string{"17"}},
-		{"id": "17", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 1, 4, 2, 1, 1, 6, 3, 2, 5, 1, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-		},
-		{
-			"7": {[]*PointTest{{2, 2, 0}, {2, 7, 0}}, 2},
-		},
-		{
-			"11": {[]*PointTest{{3, 3, 0}, {3, 11, 0}}, 3},
-		},
-		{
-			"15": {[]*PointTest{{0, 4, 0}, {0, 15, 0}}, 0},
-			"6":  {[]*PointTest{{0, 4, 0}, {4, 4, 2}, {4, 6, 0}}, 4},
-		},
-		{
-			"8": {[]*PointTest{{1, 5, 0}, {1, 8, 0}}, 1},
-			"6": {[]*PointTest{{1, 5, 0}, {4, 5, 2}, {4, 6, 0}}, 4},
-		},
-		{
-			"15": {[]*PointTest{{4, 6, 0}, {4, 14, 1}, {2, 14, 0}, {2, 15, 1}, {0, 15, 0}}, 4},
-		},
-		{
-			"12": {[]*PointTest{{2, 7, 0}, {2, 12, 0}}, 2},
-		},
-		{
-			"9":  {[]*PointTest{{1, 8, 0}, {1, 9, 0}}, 1},
-			"13": {[]*PointTest{{1, 8, 0}, {5, 8, 2}, {5, 13, 0}}, 5},
-		},
-		{
-			"14": {[]*PointTest{{1, 9, 0}, {1, 14, 0}}, 1},
-			"10": {[]*PointTest{{1, 9, 0}, {6, 9, 2}, {6, 10, 0}}, 6},
-		},
-		{
-			"14": {[]*PointTest{{6, 10, 0}, {6, 14, 1}, {1, 14, 0}}, 6},
-		},
-		{
-			"14": {[]*PointTest{{3, 11, 0}, {3, 14, 1}, {1, 14, 0}}, 3},
-		},
-		{
-			"14": {[]*PointTest{{2, 12, 0}, {2, 14, 1}, {1, 14, 0}}, 2},
-		},
-		{
-			"14": {[]*PointTest{{5, 13, 0}, {5, 14, 1}, {1, 14, 0}}, 5},
-		},
-		{
-			"16": {[]*PointTest{{1, 14, 0}, {1, 16, 0}}, 1},
-		},
-		{
-			"17": {[]*PointTest{{0, 15, 0}, {0, 17, 0}}, 0},
-		},
-		{
-			"17": {[]*PointTest{{1, 16, 0}, {1, 17, 1}, {0, 17, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test28(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"2", "1"}},
-		{"id": "1", "parents": []string{"2"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"6", "5"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 0, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"2": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-			"1": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"2": {[]*PointTest{{1, 1, 0}, {1, 2, 1}, {0, 2, 0}}, 1},
-		},
-		{
-			"3": {[]*PointTest{{0, 2, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{0, 3, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-			"5": {[]*PointTest{{0, 4, 0}, {1, 4, 2}, {1, 5, 0}}, 1},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test29(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"7"}},
-		{"id": "1", "parents": []string{"15"}},
-		{"id": "2", "parents": []string{"17"}},
-		{"id": "3", "parents": []string{"8"}},
-		{"id": "4", "parents": []string{"18"}},
-		{"id": "5", "parents": []string{"12"}},
-		{"id": "6", "parents": []string{"20"}},
-		{"id": "7", "parents": []string{"9", "10"}},
-		{"id": "8", "parents": []string{"9", "11"}},
-		{"id": "9", "parents": []string{"13", "14"}},
-		{"id": "10", "parents": []string{"21"}},
-		{"id": "11", "parents": []string{"13"}},
-		{"id": "12", "parents": []string{"14"}},
-		{"id": "13", "parents": []string{"16", "15"}},
-		{"id": "14", "parents": []string{"19"}},
-		{"id": "15", "parents": []string{"26"}},
-		{"id": "16", "parents": []string{"27"}},
-		{"id": "17", "parents": []string{"25"}},
-		{"id": "18", "parents": []string{"24"}},
-		{"id": "19", "parents": []string{"23"}},
-		{"id": "20", "parents": []string{"22"}},
-		{"id": "21", "parents": []string{"22"}},
-		{"id": "22", "parents": []string{"23"}},
-		{"id": "23", "parents": []string{"24"}},
-		{"id": "24", "parents": []string{"25"}},
-		{"id": "25", "parents": []string{"26"}},
-		{"id": "26", "parents": []string{"27"}},
-		{"id": "27", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 4, 5, 6, 0, 3, 0, 7, 3, 5, 0, 4, 1, 0, 2, 3, 4, 5, 6, 5, 4, 3, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"7": {[]*PointTest{{0, 0, 0}, {0, 7, 0}}, 0},
-		},
-		{
-			"15": {[]*PointTest{{1, 1, 0}, {1, 15, 0}}, 1},
-		},
-		{
-			"17": {[]*PointTest{{2, 2, 0}, {2, 17, 0}}, 2},
-		},
-		{
-			"8": {[]*PointTest{{3, 3, 0}, {3, 8, 0}}, 3},
-		},
-		{
-			"18": {[]*PointTest{{4, 4, 0}, {4, 13, 1}, {3, 13, 0}, {3, 18, 0}}, 4},
-		},
-		{
-			"12": {[]*PointTest{{5, 5, 0}, {5, 12, 0}}, 5},
-		},
-		{
-			"20": {[]*PointTest{{6, 6, 0}, {6, 13, 1}, {5, 13, 0}, {5, 20, 0}}, 6},
-		},
-		{
-			"9":  {[]*PointTest{{0, 7, 0}, {0, 9, 0}}, 0},
-			"10": {[]*PointTest{{0, 7, 0}, {7, 7, 2}, {7, 10, 0}}, 7},
-		},
-		{
-			"9":  {[]*PointTest{{3, 8, 0}, {0, 8, 3}, {0, 9, 0}}, 0},
-			"11": {[]*PointTest{{3, 8, 0}, {3, 11, 0}}, 3},
-		},
-		{
-			"13": {[]*PointTest{{0, 9, 0}, {0, 13, 0}}, 0},
-			"14": {[]*PointTest{{0, 9, 0}, {8, 9, 2}, {8, 13, 1}, {7, 13, 0}, {7, 14, 1}, {4, 14, 0}}, 8},
-		},
-		{
-			"21": {[]*PointTest{{7, 10, 0}, {7, 13, 1}, {6, 13, 0}, {6, 21, 0}}, 7},
-		},
-		{
-			"13": {[]*PointTest{{3, 11, 0}, {3, 13, 1}, {0, 13, 0}}, 3},
-		},
-		{
-			"14": {[]*PointTest{{5, 12, 0}, {5, 13, 1}, {4, 13, 0}, {4, 14, 0}}, 5},
-		},
-		{
-			"16": {[]*PointTest{{0, 13, 0}, {0, 16, 0}}, 0},
-			"15": {[]*PointTest{{0, 13, 0}, {1, 13, 2}, {1, 15, 0}}, 1},
-		},
-		{
-			"19": {[]*PointTest{{4, 14, 0}, {4, 19, 0}}, 5},
-		},
-		{
-			"26": {[]*PointTest{{1, 15, 0}, {1, 26, 0}}, 1},
-		},
-		{
-			"27": {[]*PointTest{{0, 16, 0}, {0, 27, 0}}, 0},
-		},
-		{
-			"25": {[]*PointTest{{2, 17, 0}, {2, 25, 0}}, 2},
-		},
-		{
-			"24": {[]*PointTest{{3, 18, 0}, {3, 24, 0}}, 4},
-		},
-		{
-			"23": {[]*PointTest{{4, 19, 0}, {4, 23, 0}}, 5},
-		},
-		{
-			"22": {[]*PointTest{{5, 20, 0}, {5, 22, 0}}, 6},
-		},
-		{
-			"22": {[]*PointTest{{6, 21, 0}, {6, 22, 1}, {5, 22, 0}}, 7},
-		},
-		{
-			"23": {[]*PointTest{{5, 22, 0}, {5, 23, 1}, {4, 23, 0}}, 6},
-		},
-		{
-			"24": {[]*PointTest{{4, 23, 0}, {4, 24, 1}, {3, 24, 0}}, 5},
-		},
-		{
-			"25": {[]*PointTest{{3, 24, 0}, {3, 25, 1}, {2, 25, 0}}, 4},
-		},
-		{
-			"26": {[]*PointTest{{2, 25, 0}, {2, 26, 1}, {1, 26, 0}}, 2},
-		},
-		{
-			"27": {[]*PointTest{{1, 26, 0}, {1, 27, 1}, {0, 27, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test30(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4"}},
-		{"id": "1", "parents": []string{"15"}},
-		{"id": "2", "parents": []string{"14"}},
-		{"id": "3", "parents": []string{"22"}},
-		{"id": "4", "parents": []string{"5", "10"}},
-		{"id": "5", "parents": []string{"6", "7"}},
-		{"id": "6", "parents": []string{"16", "8"}},
-		{"id": "7", "parents": []string{"9"}},
-		{"id": "8", "parents": []string{"11"}},
-		{"id": "9", "parents": []string{"16"}},
-		{"id": "10", "parents": []string{"18"}},
-		{"id": "11", "parents": []string{"12"}},
-		{"id": "12", "parents": []string{"13", "16"}},
-		{"id": "13", "parents": []string{"21"}},
-		{"id": "14", "parents": []string{"18"}},
-		{"id": "15", "parents": []string{"23"}},
-		{"id": "16", "parents": []string{"18", "17"}},
-		{"id": "17", "parents": []string{"18"}},
-		{"id": "18", "parents": []string{"20", "19"}},
-		{"id": "19", "parents": []string{"20"}},
-		{"id": "20", "parents": []string{"24"}},
-		{"id": "21", "parents": []string{"22"}},
-		{"id": "22", "parents": []string{"23"}},
-		{"id": "23", "parents": []string{"24"}},
-		{"id": "24", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 0, 0, 0, 5, 6, 5, 4, 6, 6, 6, 2, 1, 0, 6, 0, 4, 0, 3, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"15": {[]*PointTest{{1, 1, 0}, {1, 15, 0}}, 1},
-		},
-		{
-			"14": {[]*PointTest{{2, 2, 0}, {2, 14, 0}}, 2},
-		},
-		{
-			"22": {[]*PointTest{{3, 3, 0}, {3, 18, 1}, {2, 18, 0}, {2, 22, 0}}, 3},
-		},
-		{
-			"5":  {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0},
-			"10": {[]*PointTest{{0, 4, 0}, {4, 4, 2}, {4, 10, 0}}, 4},
-		},
-		{
-			"6": {[]*PointTest{{0, 5, 0}, {0, 6, 0}}, 0},
-			"7": {[]*PointTest{{0, 5, 0}, {5, 5, 2}, {5, 7, 0}}, 5},
-		},
-		{
-			"16": {[]*PointTest{{0, 6, 0}, {0, 16, 0}}, 0},
-			"8":  {[]*PointTest{{0, 6, 0}, {6, 6, 2}, {6, 8, 0}}, 6},
-		},
-		{
-			"9": {[]*PointTest{{5, 7, 0}, {5, 9, 0}}, 5},
-		},
-		{
-			"11": {[]*PointTest{{6, 8, 0}, {6, 11, 0}}, 6},
-		},
-		{
-			"16": {[]*PointTest{{5, 9, 0}, {5, 16, 1}, {0, 16, 0}}, 5},
-		},
-		{
-			"18": {[]*PointTest{{4, 10, 0}, {4, 18, 1}, {0, 18, 0}}, 4},
-		},
-		{
-			"12": {[]*PointTest{{6, 11, 0}, {6, 12, 0}}, 6},
-		},
-		{
-			"13": {[]*PointTest{{6, 12, 0}, {6, 13, 0}}, 6},
-			"16": {[]*PointTest{{6, 12, 0}, {0, 12, 3}, {0, 16, 0}}, 0},
-		},
-		{
-			"21": {[]*PointTest{{6, 13, 0}, {6, 16, 1}, {5, 16, 0}, {5, 18, 1}, {3, 18, 0}, {3, 21, 0}}, 6},
-		},
-		{
-			"18": {[]*PointTest{{2, 14, 0}, {2, 18, 1}, {0, 18, 0}}, 2},
-		},
-		{
-			"23": {[]*PointTest{{1, 15, 0}, {1, 23, 0}}, 1},
-		},
-		{
-			"18": {[]*PointTest{{0, 16, 0}, {0, 18, 0}}, 0},
-			"17": {[]*PointTest{{0, 16, 0}, {6, 16, 2}, {6, 17, 0}}, 7},
-		},
-		{
-			"18": {[]*PointTest{{6, 17, 0}, {6, 18, 1}, {0, 18, 0}}, 7},
-		},
-		{
-			"20": {[]*PointTest{{0, 18, 0}, {0, 20, 0}}, 0},
-			"19": {[]*PointTest{{0, 18, 0}, {4, 18, 2}, {4, 19, 0}}, 5},
-		},
-		{
-			"20": {[]*PointTest{{4, 19, 0}, {4, 20, 1}, {0, 20, 0}}, 5},
-		},
-		{
-			"24": {[]*PointTest{{0, 20, 0}, {0, 24, 0}}, 0},
-		},
-		{
-			"22": {[]*PointTest{{3, 21, 0}, {3, 22, 1}, {2, 22, 0}}, 6},
-		},
-		{
-			"23": {[]*PointTest{{2, 22, 0}, {2, 23, 1}, {1, 23, 0}}, 3},
-		},
-		{
-			"24": {[]*PointTest{{1, 23, 0}, {1, 24, 1}, {0, 24, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test31(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"3"}},
-		{"id": "1", "parents": []string{"4"}},
-		{"id": "2", "parents": []string{"5", "4"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 4, 1}, {1, 4, 0}, {1, 5, 0}}, 2},
-			"4": {[]*PointTest{{2, 2, 0}, {1, 2, 3}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{0, 3, 0}, {0, 4, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test32(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"2"}},
-		{"id": "1", "parents": []string{"5", "3"}},
-		{"id": "2", "parents": []string{"3", "4"}},
-		{"id": "3", "parents": []string{"6"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 0, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"2": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-			"3": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 3, 1}, {0, 3, 0}}, 2},
-		},
-		{
-			"3": {[]*PointTest{{0, 2, 0}, {0, 3, 0}}, 0},
-			"4": {[]*PointTest{{0, 2, 0}, {3, 2, 2}, {3, 3, 1}, {2, 3, 0}, {2, 4, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{0, 3, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{2, 4, 0}, {2, 5, 1}, {1, 5, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test33(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"3"}},
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"7"}},
-		{"id": "3", "parents": []string{"9", "4"}},
-		{"id": "4", "parents": []string{"9"}},
-		{"id": "5", "parents": []string{"8", "6"}},
-		{"id": "6", "parents": []string{"10"}},
-		{"id": "7", "parents": []string{"10"}},
-		{"id": "8", "parents": []string{"10"}},
-		{"id": "9", "parents": []string{"13"}},
-		{"id": "10", "parents": []string{"12", "11"}},
-		{"id": "11", "parents": []string{"12"}},
-		{"id": "12", "parents": []string{"13"}},
-		{"id": "13", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 0, 3, 1, 4, 2, 1, 0, 1, 2, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"3": {[]*PointTest{{0, 0, 0}, {0, 3, 0}}, 0},
-		},
-		{
-			"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1},
-		},
-		{
-			"7": {[]*PointTest{{2, 2, 0}, {2, 7, 0}}, 2},
-		},
-		{
-			"9": {[]*PointTest{{0, 3, 0}, {0, 9, 0}}, 0},
-			"4": {[]*PointTest{{0, 3, 0}, {3, 3, 2}, {3, 4, 0}}, 3},
-		},
-		{
-			"9": {[]*PointTest{{3, 4, 0}, {3, 9, 1}, {0, 9, 0}}, 3},
-		},
-		{
-			"8": {[]*PointTest{{1, 5, 0}, {1, 8, 0}}, 1},
-			"6": {[]*PointTest{{1, 5, 0}, {4, 5, 2}, {4, 6, 0}}, 4},
-		},
-		{
-			"10": {[]*PointTest{{4, 6, 0}, {4, 9, 1}, {3, 9, 0}, {3, 10, 1}, {1, 10, 0}}, 4},
-		},
-		{
-			"10": {[]*PointTest{{2, 7, 0}, {2, 10, 1}, {1, 10, 0}}, 2},
-		},
-		{
-			"10": {[]*PointTest{{1, 8, 0}, {1, 10, 0}}, 1},
-		},
-		{
-			"13": {[]*PointTest{{0, 9, 0}, {0, 13, 0}}, 0},
-		},
-		{
-			"12": {[]*PointTest{{1, 10, 0}, {1, 12, 0}}, 1},
-			"11": {[]*PointTest{{1, 10, 0}, {2, 10, 2}, {2, 11, 0}}, 5},
-		},
-		{
-			"12": {[]*PointTest{{2, 11, 0}, {2, 12, 1}, {1, 12, 0}}, 5},
-		},
-		{
-			"13": {[]*PointTest{{1, 12, 0}, {1, 13, 1}, {0, 13, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test34(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"4", "2"}},
-		{"id": "2", "parents": []string{"3"}},
-		{"id": "3", "parents": []string{"4", "5"}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"7"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 2, 1, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1},
-			"2": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 2, 0}}, 2},
-		},
-		{
-			"3": {[]*PointTest{{2, 2, 0}, {2, 3, 0}}, 2},
-		},
-		{
-			"4": {[]*PointTest{{2, 3, 0}, {2, 4, 1}, {1, 4, 0}}, 2},
-			"5": {[]*PointTest{{2, 3, 0}, {0, 3, 3}, {0, 5, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 4, 0}, {1, 6, 0}}, 1},
-		},
-		{
-			"7": {[]*PointTest{{0, 5, 0}, {0, 7, 0}}, 0},
-		},
-		{
-			"7": {[]*PointTest{{1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 1},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test35(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4", "1"}},
-		{"id": "1", "parents": []string{"2", "3"}},
-		{"id": "2", "parents": []string{}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 1, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-			"1": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"2": {[]*PointTest{{1, 1, 0}, {1, 2, 0}}, 1},
-			"3": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 3, 1}, {1, 3, 0}}, 2},
-		},
-		{},
-		{
-			"4": {[]*PointTest{{1, 3, 0}, {1, 4, 1}, {0, 4, 0}}, 2},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test36(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"4", "1"}},
-		{"id": "1", "parents": []string{"4", "2"}},
-		{"id": "2", "parents": []string{"3", "5"}},
-		{"id": "3", "parents": []string{}},
-		{"id": "4", "parents": []string{"6"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 2, 0, 1, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"4": {[]*PointTest{{0, 0, 0}, {0, 4, 0}}, 0},
-			"1": {[]*PointTest{{0, 0, 0}, {1, 0, 2}, {1, 1, 0}}, 1},
-		},
-		{
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-			"2": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 2, 0}}, 2},
-		},
-		{
-			"3": {[]*PointTest{{2, 2, 0}, {2, 3, 0}}, 2},
-			"5": {[]*PointTest{{2, 2, 0}, {3, 2, 2}, {3, 4, 1}, {1, 4, 0}, {1, 5, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{1, 5, 0}, {1, 6, 1}, {0, 6, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{0, 4, 0}, {0, 6, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test37(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"6"}},
-		{"id": "2", "parents": []string{"5"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"8", "7"}},
-		{"id": "5", "parents": []string{"6"}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 3, 0, 0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{
-			"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0},
-		},
-		{
-			"6": {[]*PointTest{{1, 1, 0}, {1, 6, 1}, {0, 6, 0}}, 1},
-		},
-		{
-			"5": {[]*PointTest{{2, 2, 0}, {2, 5, 1}, {0, 5, 0}}, 2},
-		},
-		{
-			"4": {[]*PointTest{{3, 3, 0}, {3, 4, 0}}, 3},
-		},
-		{
-			"7": {[]*PointTest{{3, 4, 0}, {4, 4, 2}, {4, 5, 1}, {3, 5, 0}, {3, 6, 1}, {2, 6, 0}, {2, 7, 1}, {0, 7, 0}}, 4},
-			"8": {[]*PointTest{{3, 4, 0}, {3, 5, 1}, {2, 5, 0}, {2, 6, 1}, {1, 6, 0}, {1, 8, 1}, {0, 8, 0}}, 3},
-		},
-		{
-			"6": {[]*PointTest{{0, 5, 0}, {0, 6, 0}}, 0},
-		},
-		{
-			"7": {[]*PointTest{{0, 6, 0}, {0, 7, 0}}, 0},
-		},
-		{
-			"8": {[]*PointTest{{0, 7, 0}, {0, 8, 0}}, 0},
-		},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test38(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"5"}},
-		{"id": "1", "parents": []string{"2"}},
-		{"id": "2", "parents": []string{"6"}},
-		{"id": "3", "parents": []string{"6"}},
-		{"id": "4", "parents": []string{"7"}},
-		{"id": "5", "parents": []string{}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 1, 2, 3, 0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{"5": {[]*PointTest{{0, 0, 0}, {0, 5, 0}}, 0}},
-		{"2": {[]*PointTest{{1, 1, 0}, {1, 2, 0}}, 1}},
-		{"6": {[]*PointTest{{1, 2, 0}, {1, 6, 1}, {0, 6, 0}}, 1}},
-		{"6": {[]*PointTest{{2, 3, 0}, {2, 6, 1}, {0, 6, 0}}, 2}},
-		{"7": {[]*PointTest{{3, 4, 0}, {3, 6, 1}, {1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 3}},
-		{},
-		{"7": {[]*PointTest{{0, 6, 0}, {0, 7, 0}}, 1}},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test39(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"6"}},
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"6"}},
-		{"id": "3", "parents": []string{"7"}},
-		{"id": "4", "parents": []string{"8"}},
-		{"id": "5", "parents": []string{}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 4, 1, 0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{"6": {[]*PointTest{{0, 0, 0}, {0, 6, 0}}, 0}},
-		{"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1}},
-		{"6": {[]*PointTest{{2, 2, 0}, {2, 6, 1}, {0, 6, 0}}, 2}},
-		{"7": {[]*PointTest{{3, 3, 0}, {3, 6, 1}, {1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 3}},
-		{"8": {[]*PointTest{{4, 4, 0}, {4, 6, 1}, {2, 6, 0}, {2, 7, 1}, {1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 4}},
-		{},
-		{"7": {[]*PointTest{{0, 6, 0}, {0, 7, 0}}, 0}},
-		{"8": {[]*PointTest{{0, 7, 0}, {0, 8, 0}}, 0}},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func Test40(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"7"}},
-		{"id": "1", "parents": []string{"5"}},
-		{"id": "2", "parents": []string{"6"}},
-		{"id": "3", "parents": []string{"8"}},
-		{"id": "4", "parents": []string{"9"}},
-		{"id": "5", "parents": []string{}},
-		{"id": "6", "parents": []string{"7"}},
-		{"id": "7", "parents": []string{"8"}},
-		{"id": "8", "parents": []string{"9"}},
-		{"id": "9", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 2, 3, 4, 1, 1, 0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{"7": {[]*PointTest{{0, 0, 0}, {0, 7, 0}}, 0}},
-		{"5": {[]*PointTest{{1, 1, 0}, {1, 5, 0}}, 1}},
-		{"6": {[]*PointTest{{2, 2, 0}, {2, 6, 1}, {1, 6, 0}}, 2}},
-		{"8": {[]*PointTest{{3, 3, 0}, {3, 6, 1}, {2, 6, 0}, {2, 7, 1}, {1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 3}},
-		{"9": {[]*PointTest{{4, 4, 0}, {4, 6, 1}, {3, 6, 0}, {3, 7, 1}, {2, 7, 0}, {2, 8, 1}, {1, 8, 0}, {1, 9, 1}, {0, 9, 0}}, 4}},
-		{},
-		{"7": {[]*PointTest{{1, 6, 0}, {1, 7, 1}, {0, 7, 0}}, 2}},
-		{"8": {[]*PointTest{{0, 7, 0}, {0, 8, 0}}, 0}},
-		{"9": {[]*PointTest{{0, 8, 0}, {0, 9, 0}}, 0}},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-// Test41 test the date-order bug where parent defined before node ends up with an infinite branch going down
-func Test41(t *testing.T) {
-	// Initial input
-	inputNodes := []*Node{
-		{"id": "0", "parents": []string{"2"}},
-		{"id": "1", "parents": []string{"4", "3"}},
-		{"id": "2", "parents": []string{"3", "1"}},
-		{"id": "3", "parents": []string{"4"}},
-		{"id": "4", "parents": []string{"5"}},
-		{"id": "5", "parents": []string{}},
-	}
-
-	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
-
-	// Expected output
-	expectedColumns := []int{0, 1, 0, 0, 0, 0}
-
-	expectedPaths := []map[string]PathTest{
-		{"2": {[]*PointTest{{0, 0, 0}, {0, 2, 0}}, 0}},
-		{
-			"3": {[]*PointTest{{1, 1, 0}, {2, 1, 2}, {2, 3, 1}, {0, 3, 0}}, 2},
-			"4": {[]*PointTest{{1, 1, 0}, {1, 4, 1}, {0, 4, 0}}, 1},
-		},
-		{
-			"3": {[]*PointTest{{0, 2, 0}, {0, 3, 0}}, 0},
-			"1": {[]*PointTest{{0, 2, 0}, {3, 2, 2}, {3, 3, 1}, {2, 3, 0}, {2, 4, 1}, {1, 4, 0}, {1, 5, 1}, {1, 5, 0}, {1, 6, 0}}, 3},
-		},
-		{"4": {[]*PointTest{{0, 3, 0}, {0, 4, 0}}, 0}},
-		{"5": {[]*PointTest{{0, 4, 0}, {0, 5, 0}}, 0}},
-	}
-
-	// Validation
-	validateColumns(t, expectedColumns, out)
-	validatePaths(t, expectedPaths, out)
-	validateColors(t, expectedPaths, out)
-}
-
-func assertEq(t *testing.T, expected, actual any) {
-	if actual != expected {
-		t.Logf("Expected: %d, Actual: %d", expected, actual)
-		t.Fail()
+func benchmarkLayout(b *testing.B, inputNodes []*Node) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetPaginated(inputNodes, "", -1); err != nil {
+			b.Logf("Failed to build tree")
+		}
 	}
 }
 
-func convertPoints[P IPoint](arr []P) (out []IPoint) {
-	for _, v := range arr {
-		out = append(out, v)
-	}
-	return
-}
+func BenchmarkLinear10k(b *testing.B)  { benchmarkLayout(b, generateLinearNodes(10_000)) }
+func BenchmarkLinear100k(b *testing.B) { benchmarkLayout(b, generateLinearNodes(100_000)) }
+func BenchmarkLinear1M(b *testing.B)   { benchmarkLayout(b, generateLinearNodes(1_000_000)) }
+func BenchmarkWide100k(b *testing.B)   { benchmarkLayout(b, generateWideNodes(100_000, 100)) }
 
-func TestPathHeight1(t *testing.T) {
-	path := &Path{Points: convertPoints([]*PointTest{
-		{0, 2, 0},
-		{3, 2, 2},
-		{3, 9, 1},
-		{2, 9, 0},
-		{2, 11, 1},
-		{1, 11, 0},
-	})}
-	assertEq(t, -1, path.GetHeightAtIdx(1))
-	assertEq(t, 3, path.GetHeightAtIdx(2))
-	assertEq(t, 3, path.GetHeightAtIdx(3))
-	assertEq(t, 2, path.GetHeightAtIdx(9))
-	assertEq(t, 2, path.GetHeightAtIdx(10))
-	assertEq(t, 1, path.GetHeightAtIdx(11))
+func BenchmarkGenerated100k(b *testing.B) {
+	cfg := gen.DefaultConfig
+	cfg.Commits, cfg.Orphans = 100_000, 5
+	benchmarkLayout(b, generateNodes(cfg))
 }
 
-func BenchmarkTest1(b *testing.B) {
+func BenchmarkPaginatedDeep100k(b *testing.B) {
+	inputNodes := generateLinearNodes(100_000)
+	from := inputNodes[90_000].GetID()
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		inputNodes := []*Node{
-			{"id": "0", "parents": []string{"4"}},
-			{"id": "1", "parents": []string{"15"}},
-			{"id": "2", "parents": []string{"14"}},
-			{"id": "3", "parents": []string{"22"}},
-			{"id": "4", "parents": []string{"5", "10"}},
-			{"id": "5", "parents": []string{"6", "7"}},
-			{"id": "6", "parents": []string{"16", "8"}},
-			{"id": "7", "parents": []string{"9"}},
-			{"id": "8", "parents": []string{"11"}},
-			{"id": "9", "parents": []string{"16"}},
-			{"id": "10", "parents": []string{"18"}},
-			{"id": "11", "parents": []string{"12"}},
-			{"id": "12", "parents": []string{"13", "16"}},
-			{"id": "13", "parents": []string{"21"}},
-			{"id": "14", "parents": []string{"18"}},
-			{"id": "15", "parents": []string{"23"}},
-			{"id": "16", "parents": []string{"18", "17"}},
-			{"id": "17", "parents": []string{"18"}},
-			{"id": "18", "parents": []string{"20", "19"}},
-			{"id": "19", "parents": []string{"20"}},
-			{"id": "20", "parents": []string{"24"}},
-			{"id": "21", "parents": []string{"22"}},
-			{"id": "22", "parents": []string{"23"}},
-			{"id": "23", "parents": []string{"24"}},
-			{"id": "24", "parents": []string{}},
-		}
-		if _, err := buildTreeTest(inputNodes, customColors, "", -1); err != nil {
+		if _, err := GetPaginated(inputNodes, from, 50); err != nil {
 			b.Logf("Failed to build tree")
 		}
 	}
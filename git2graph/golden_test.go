@@ -0,0 +1,112 @@
+package git2graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// Regenerate the goldens with "go test ./git2graph -run 'TestGolden|TestVisual' -update"
+var update = flag.Bool("update", false, "regenerate the golden files of the fixtures")
+
+// Return the golden file of a fixture, data/test_NNN.json has its expected outputs in data/test_NNN.golden.json
+func goldenFile(file string) string {
+	return strings.TrimSuffix(file, ".json") + ".golden.json"
+}
+
+// Return the options of a fixture, data/test_NNN.options.json sets the layout options of data/test_NNN.json when it exists
+func fixtureOptions(file string) (*Options, error) {
+	b, err := os.ReadFile(strings.TrimSuffix(file, ".json") + ".options.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return &Options{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixtureOpts struct {
+		PriorityRefs []string `json:"priorityRefs"`
+	}
+	if err := json.Unmarshal(b, &fixtureOpts); err != nil {
+		return nil, err
+	}
+	return &Options{PriorityRefs: fixtureOpts.PriorityRefs}, nil
+}
+
+// Encode the full and rows outputs of a fixture, one node per line
+func encodeGolden(file string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, layout := range []struct {
+		name   string
+		layout func(context.Context, []*Node, *Options) (*Out, error)
+	}{{"full", LayoutContext}, {"rows", LayoutRowsContext}} {
+		inputNodes, err := GetInputNodesFromFile(file)
+		if err != nil {
+			return nil, err
+		}
+		opts, err := fixtureOptions(file)
+		if err != nil {
+			return nil, err
+		}
+		out, err := layout.layout(context.Background(), inputNodes, opts)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`  "` + layout.name + `": [`)
+		for j, node := range out.Nodes {
+			b, err := json.Marshal(node)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(utils.Ternary(j == 0, "\n    ", ",\n    "))
+			buf.Write(b)
+		}
+		buf.WriteString(utils.Ternary(i == 0, "\n  ],\n", "\n  ]\n"))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// Every data/test_NNN.json fixture has the full and rows outputs of its golden file
+func TestGolden(t *testing.T) {
+	files, _ := filepath.Glob("../data/test_[0-9][0-9][0-9].json")
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			actual, err := encodeGolden(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *update {
+				if err := os.WriteFile(goldenFile(file), actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenFile(file))
+			if err != nil {
+				t.Fatalf("%v, regenerate the goldens with -update", err)
+			}
+			expectedLines, actualLines := strings.Split(string(expected), "\n"), strings.Split(string(actual), "\n")
+			for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+				var expectedLine, actualLine string
+				if i < len(expectedLines) {
+					expectedLine = expectedLines[i]
+				}
+				if i < len(actualLines) {
+					actualLine = actualLines[i]
+				}
+				if expectedLine != actualLine {
+					t.Fatalf("%s:%d\nExpected: %s\nActual:   %s", goldenFile(file), i+1, expectedLine, actualLine)
+				}
+			}
+		})
+	}
+}
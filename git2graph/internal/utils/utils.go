@@ -0,0 +1,10 @@
+// Package utils has the small helpers shared by the git2graph packages.
+package utils
+
+// Ternary returns a if predicate is true, b otherwise
+func Ternary[T any](predicate bool, a, b T) T {
+	if predicate {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,508 @@
+package git2graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// LaneAssigner is a layout strategy, it assigns a column to every node and routes the paths in between them
+type LaneAssigner interface {
+	Name() string
+	assignLanes(ctx context.Context, inputNodes []*Node, opts *Options) ([]*internalNode, []*Path, error)
+}
+
+var (
+	GreedyLanes  LaneAssigner = greedyLanes{}  // Reproduces the SourceTree layout (default)
+	CompactLanes LaneAssigner = compactLanes{} // Reuses the free columns and reorders the lanes, for fewer columns and crossings
+)
+
+// LaneAssigners returns the available layout strategies
+func LaneAssigners() []LaneAssigner {
+	return []LaneAssigner{GreedyLanes, CompactLanes}
+}
+
+// GetLaneAssigner returns the layout strategy with the given name
+func GetLaneAssigner(name string) (LaneAssigner, error) {
+	for _, assigner := range LaneAssigners() {
+		if assigner.Name() == name {
+			return assigner, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown lane assigner %q", name)
+}
+
+type greedyLanes struct{}
+
+func (greedyLanes) Name() string { return "greedy" }
+
+// compactLanes lays out the graph like "git log --graph": every column is a lane waiting for a commit.
+// A node takes the leftmost lane waiting for it, the other lanes waiting for it merge into it on its row.
+// Its first parent continues in its lane, and a parent no lane is waiting for yet gets the free lane
+// its path crosses the fewest lanes to, so that the columns are reused.
+// Once all the paths are routed, the lanes move to other columns, or swap their columns, while it reduces
+// the crossings (the columns are kept as routed when the input is not in topological order).
+// The layout cache is not used.
+type compactLanes struct{}
+
+func (compactLanes) Name() string { return "compact" }
+
+type compactLane struct {
+	waitFor  *internalNode // Nil for a free lane
+	span     *laneSpan
+	colorIdx int
+	laneTip  string
+	paths    []*Path // Paths going down the lane, to waitFor
+}
+
+func (compactLanes) assignLanes(ctx context.Context, inputNodes []*Node, opts *Options) (nodes []*internalNode, partialPaths []*Path, err error) {
+	inputNodes, nbPriorityLanes := withPriorityLanes(inputNodes, opts.PriorityRefs)
+	colorsMan := newColorsManager()
+	byID := make(map[string]*internalNode, len(inputNodes))
+	getNode := func(id string) *internalNode {
+		node, ok := byID[id]
+		if !ok {
+			node = newNode(id, -1)
+			byID[id] = node
+		}
+		return node
+	}
+	var lanes []*compactLane
+	lanesWaitingFor := func(node *internalNode) (out []int) {
+		for i, lane := range lanes {
+			if lane.waitFor == node {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+	// Return the number of lanes going down through the row idx in between the columns a and b,
+	// that a path going across from a to b on that row crosses
+	nbCrossed := func(idx, a, b int) (nb int) {
+		for i := min(a, b) + 1; i < max(a, b) && i < len(lanes); i++ {
+			if lanes[i].waitFor != nil && lanes[i].span.start < idx {
+				nb++
+			}
+		}
+		return nb
+	}
+	// Return the free lane a path going across from column on the row idx crosses the fewest lanes to,
+	// the nearest one, left first, when they cross as many, and len(lanes) for a new lane only if it crosses fewer
+	freeLane := func(idx, column int) int {
+		best, bestCrossed := -1, 0
+		for d := 0; column-d >= 0 || column+d < len(lanes); d++ {
+			for _, i := range []int{column - d, column + d} {
+				if i >= 0 && i < len(lanes) && lanes[i].waitFor == nil {
+					if crossed := nbCrossed(idx, column, i); best == -1 || crossed < bestCrossed {
+						best, bestCrossed = i, crossed
+					}
+				}
+			}
+		}
+		if best == -1 || nbCrossed(idx, column, len(lanes)) < bestCrossed {
+			return len(lanes)
+		}
+		return best
+	}
+	// The columns are the columns of the spans of the lanes, which reduceCrossings can change once all the paths are routed
+	var spans []*laneSpan
+	newSpan := func(column, idx int) *laneSpan {
+		span := &laneSpan{column: column, start: idx, end: idx, frozen: idx < nbPriorityLanes}
+		spans = append(spans, span)
+		return span
+	}
+	pointsSpans := make(map[*Point]*laneSpan)
+	addPoint := func(path *Path, span *laneSpan, y *int, typ pointType) {
+		point := newPoint(span.column, y, typ)
+		pointsSpans[point] = span
+		span.end = max(span.end, *y)
+		path.noDupAppend(point)
+	}
+	nodesSpans := make(map[*internalNode]*laneSpan, len(inputNodes))
+	topological := true
+	closedPathPoints := 0 // Points of the paths arriving on the rows laid out, which do not change anymore
+
+	for idx, rawNode := range inputNodes {
+		if idx%cancelCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		node := getNode(rawNode.GetID())
+		*node.idx = idx
+		node.initialNode = rawNode
+		nodes = append(nodes, node)
+
+		// Column of the node, and the paths arriving on it
+		waiting := lanesWaitingFor(node)
+		if len(waiting) == 0 {
+			column := freeLane(idx, 0)
+			if column == len(lanes) {
+				lanes = append(lanes, &compactLane{})
+			}
+			lanes[column] = &compactLane{span: newSpan(column, idx), colorIdx: colorsMan.getColor(idx), laneTip: node.id}
+			waiting = []int{column}
+		}
+		node.setColumn(waiting[0])
+		lane := lanes[node.column]
+		node.setColor(lane.colorIdx, lane.laneTip)
+		nodeSpan := lane.span
+		nodesSpans[node] = nodeSpan
+		nodeSpan.end = idx
+		for _, i := range waiting {
+			for _, path := range lanes[i].paths {
+				if i != node.column {
+					addPoint(path, lanes[i].span, node.idx, MergeBack)
+				}
+				addPoint(path, nodeSpan, node.idx, Pipe)
+				closedPathPoints += path.len()
+			}
+			if i != node.column {
+				colorsMan.releaseColor(lanes[i].colorIdx, idx)
+			}
+			lanes[i] = &compactLane{}
+		}
+
+		// Paths leaving the node
+		laneUsed := false
+		for i, parentID := range rawNode.GetParents() {
+			parent := getNode(parentID)
+			parent.children = append(parent.children, node)
+			node.parents = append(node.parents, parent)
+			path := node.pathTo(parent)
+			addPoint(path, nodeSpan, node.idx, Pipe)
+			if *parent.idx != -1 {
+				// Parent above its child, the input is not in topological order
+				topological = false
+				path.noDupAppend(newPoint(parent.column, parent.idx, Pipe))
+				path.setColor(node.colorIdx, node.laneTip)
+				closedPathPoints += path.len()
+				continue
+			}
+			target := -1
+			waitingParent := lanesWaitingFor(parent)
+			if len(waitingParent) > 0 {
+				target = waitingParent[0]
+			}
+			if target != -1 && nbCrossed(idx, node.column, target) == 0 {
+				// Join the lane already waiting for the parent
+			} else if i == 0 {
+				// The lane of the node goes down to the parent, and merges into the other lanes waiting for it on its row
+				target = node.column
+				lanes[target] = &compactLane{waitFor: parent, span: nodeSpan, colorIdx: node.colorIdx, laneTip: node.laneTip}
+			} else if free := freeLane(idx, node.column); target == -1 || nbCrossed(idx, node.column, free) < nbCrossed(idx, node.column, target) {
+				// A new lane to the parent, or one that crosses fewer lanes than joining the lane waiting for it
+				if free == len(lanes) {
+					lanes = append(lanes, &compactLane{})
+				}
+				target = free
+				span := nodeSpan
+				if target != node.column {
+					span = newSpan(target, idx)
+				}
+				lanes[target] = &compactLane{waitFor: parent, span: span, colorIdx: colorsMan.getColor(idx), laneTip: parent.id}
+			}
+			laneUsed = laneUsed || target == node.column
+			if target != node.column {
+				addPoint(path, lanes[target].span, node.idx, utils.Ternary(target > node.column, Fork, MergeTo))
+			}
+			path.setColor(lanes[target].colorIdx, lanes[target].laneTip)
+			lanes[target].paths = append(lanes[target].paths, path)
+		}
+		if !laneUsed {
+			colorsMan.releaseColor(node.colorIdx, idx)
+		}
+		for len(lanes) > 0 && lanes[len(lanes)-1].waitFor == nil {
+			lanes = lanes[:len(lanes)-1]
+		}
+		if err = checkLimit(ColumnsLimit, opts.MaxColumns, len(lanes)); err != nil {
+			return nil, nil, err
+		}
+		if opts.MaxPathPoints > 0 {
+			openPathPoints := 0
+			for _, lane := range lanes {
+				for _, path := range lane.paths {
+					openPathPoints += path.len()
+				}
+			}
+			if err = checkLimit(PathPointsLimit, opts.MaxPathPoints, closedPathPoints+openPathPoints); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Parents missing from the input are below the last row
+	for _, lane := range lanes {
+		if lane.waitFor != nil && *lane.waitFor.idx == -1 {
+			*lane.waitFor.idx = len(inputNodes)
+			for _, path := range lane.paths {
+				addPoint(path, lane.span, lane.waitFor.idx, Pipe)
+				closedPathPoints += path.len()
+			}
+		}
+	}
+	if err = checkLimit(PathPointsLimit, opts.MaxPathPoints, closedPathPoints); err != nil {
+		return nil, nil, err
+	}
+
+	if topological {
+		var paths []*Path
+		for _, node := range nodes {
+			for _, parent := range node.parents {
+				paths = append(paths, node.parentsPaths[parent.id])
+			}
+		}
+		reduceCrossings(spans, paths, pointsSpans, len(inputNodes)+1)
+		for point, span := range pointsSpans {
+			point.x = span.column
+		}
+		for node, span := range nodesSpans {
+			node.setColumn(span.column)
+		}
+		for _, path := range paths {
+			retypeTurns(path)
+		}
+	}
+
+	from, origLimit := opts.From, opts.limit()
+	fromIdx := nbPriorityLanes
+	if from != "" {
+		fromNode, ok := byID[from]
+		if !ok || *fromNode.idx < 0 || *fromNode.idx >= len(inputNodes) {
+			return nil, nil, nil
+		}
+		fromIdx = *fromNode.idx + 1
+		for _, node := range nodes[:fromIdx] {
+			if node.isPriorityLane() {
+				continue
+			}
+			for _, parent := range node.parents {
+				if *parent.idx >= fromIdx {
+					partialPaths = append(partialPaths, node.parentsPaths[parent.id])
+				}
+			}
+		}
+	}
+	cropPartialPaths(partialPaths, fromIdx, origLimit)
+	cropNodesPaths(nodes, fromIdx, origLimit)
+	nodes = removePriorityLanes(sliceResults(nodes, fromIdx, origLimit), partialPaths, nbPriorityLanes)
+	return nodes, partialPaths, nil
+}
+
+// A lane of the compact layout from the row it starts on to the row it ends on, in a column.
+// Lanes whose rows overlap, ends included, cannot be in the same column.
+type laneSpan struct {
+	column     int
+	start, end int
+	frozen     bool // Lane of a priority ref, pinned to its column
+}
+
+// A path going across the columns of two lanes on a row
+type spansCrossing struct {
+	row  int
+	a, b *laneSpan
+}
+
+// Move the lanes into other columns, or swap the columns of two lanes, when it makes the paths cross fewer lanes,
+// until no move does. The number of columns does not change.
+func reduceCrossings(spans []*laneSpan, paths []*Path, pointsSpans map[*Point]*laneSpan, nbRows int) {
+	// Lanes of each column, by start row. They do not overlap, so their end rows are in order too
+	nbColumns := 0
+	byColumn := make(map[int][]*laneSpan)
+	byStart := func(a, b *laneSpan) int {
+		if a.start != b.start {
+			return cmp.Compare(a.start, b.start)
+		}
+		return cmp.Compare(a.end, b.end)
+	}
+	for _, span := range spans {
+		nbColumns = max(nbColumns, span.column+1)
+		byColumn[span.column] = append(byColumn[span.column], span)
+	}
+	for _, columnSpans := range byColumn {
+		slices.SortStableFunc(columnSpans, byStart)
+	}
+	// Paths going across the columns on each row, and lanes going down through each row
+	across := make([][]spansCrossing, nbRows)
+	for _, path := range paths {
+		for i := 1; i < len(path.Points); i++ {
+			p1, _ := path.Points[i-1].(*Point)
+			p2, _ := path.Points[i].(*Point)
+			a, b := pointsSpans[p1], pointsSpans[p2]
+			if a != nil && b != nil && a != b && *p1.y == *p2.y {
+				across[*p1.y] = append(across[*p1.y], spansCrossing{*p1.y, a, b})
+			}
+		}
+	}
+	rows := make([]int, 0)
+	for row := range across {
+		if len(across[row]) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	// Lanes going down through the rows that have horizontals, which stay the same as the lanes change columns
+	through := make([][]*laneSpan, nbRows)
+	byStartRow := slices.Clone(spans)
+	slices.SortFunc(byStartRow, byStart)
+	active := make([]*laneSpan, 0)
+	for _, row := range rows {
+		for ; len(byStartRow) > 0 && byStartRow[0].start < row; byStartRow = byStartRow[1:] {
+			active = append(active, byStartRow[0])
+		}
+		active = slices.DeleteFunc(active, func(span *laneSpan) bool { return span.end <= row })
+		through[row] = slices.Clone(active)
+	}
+	// Horizontals starting or ending in each lane
+	ends := make(map[*laneSpan][]spansCrossing)
+	for _, row := range rows {
+		for _, h := range across[row] {
+			ends[h.a] = append(ends[h.a], h)
+			ends[h.b] = append(ends[h.b], h)
+		}
+	}
+	between := func(h spansCrossing, column int) bool {
+		return min(h.a.column, h.b.column) < column && column < max(h.a.column, h.b.column)
+	}
+	// Count the crossings of the horizontals and the lanes going down through their rows that involve span or other,
+	// the only ones that change when they move
+	nbCrossings := func(span, other *laneSpan) (nb int) {
+		involves := func(h spansCrossing) bool {
+			return h.a == span || h.b == span || h.a == other || h.b == other
+		}
+		for _, moved := range []*laneSpan{span, other} {
+			if moved == nil {
+				continue
+			}
+			for _, h := range ends[moved] {
+				if moved == other && (h.a == span || h.b == span) {
+					continue
+				}
+				for _, through := range through[h.row] {
+					if between(h, through.column) {
+						nb++
+					}
+				}
+			}
+			from, _ := slices.BinarySearch(rows, moved.start+1)
+			for _, row := range rows[from:] {
+				if row >= moved.end {
+					break
+				}
+				for _, h := range across[row] {
+					if !involves(h) && between(h, moved.column) {
+						nb++
+					}
+				}
+			}
+		}
+		return nb
+	}
+	// Return the first lane of the column that overlaps span, other than except, and how many do up to two
+	conflicts := func(span *laneSpan, column int, except *laneSpan) (first *laneSpan, nb int) {
+		columnSpans := byColumn[column]
+		after, _ := slices.BinarySearchFunc(columnSpans, span.end+1, func(other *laneSpan, row int) int { return cmp.Compare(other.start, row) })
+		for i := after - 1; i >= 0 && columnSpans[i].end >= span.start && nb < 2; i-- {
+			if other := columnSpans[i]; other != span && other != except {
+				first, nb = utils.Ternary(first == nil, other, first), nb+1
+			}
+		}
+		return first, nb
+	}
+	setColumn := func(span *laneSpan, column int) {
+		i, _ := slices.BinarySearchFunc(byColumn[span.column], span, byStart)
+		for byColumn[span.column][i] != span {
+			i++
+		}
+		byColumn[span.column] = slices.Delete(byColumn[span.column], i, i+1)
+		span.column = column
+		i, _ = slices.BinarySearchFunc(byColumn[column], span, byStart)
+		byColumn[column] = slices.Insert(byColumn[column], i, span)
+	}
+	// Move span to column, and other to the column of span if it is not nil, if the paths then cross fewer lanes
+	tryMove := func(span *laneSpan, column int, other *laneSpan) bool {
+		before, from := nbCrossings(span, other), span.column
+		span.column = column
+		if other != nil {
+			other.column = from
+		}
+		after := nbCrossings(span, other)
+		span.column = from
+		if other != nil {
+			other.column = column
+		}
+		if after >= before {
+			return false
+		}
+		setColumn(span, column)
+		if other != nil {
+			setColumn(other, from)
+		}
+		return true
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for _, span := range spans {
+			if span.frozen {
+				continue
+			}
+			for column := 0; column < nbColumns; column++ {
+				if column == span.column {
+					continue
+				}
+				switch other, nb := conflicts(span, column, nil); {
+				case nb == 0:
+					improved = tryMove(span, column, nil) || improved
+				case nb == 1 && !other.frozen:
+					if _, nb := conflicts(other, span.column, span); nb == 0 {
+						improved = tryMove(span, column, other) || improved
+					}
+				}
+			}
+		}
+	}
+}
+
+// Set the types of the points going across the columns after the columns changed, Fork going right and MergeTo going left
+func retypeTurns(path *Path) {
+	for i := 1; i < len(path.Points); i++ {
+		if p, ok := path.Points[i].(*Point); ok && (p.typ == Fork || p.typ == MergeTo) {
+			p.typ = utils.Ternary(p.x > path.Points[i-1].getX(), Fork, MergeTo)
+		}
+	}
+}
+
+// WriteLanesReport lays out every file with every lane assigner, and writes their columns and crossings side by side
+func WriteLanesReport(w io.Writer, files []string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprint(tw, "file\t")
+	for _, assigner := range LaneAssigners() {
+		_, _ = fmt.Fprintf(tw, "%s columns\t%s crossings\t", assigner.Name(), assigner.Name())
+	}
+	_, _ = fmt.Fprintln(tw)
+	for _, file := range files {
+		_, _ = fmt.Fprintf(tw, "%s\t", file)
+		for _, assigner := range LaneAssigners() {
+			inputNodes, err := GetInputNodesFromFile(file)
+			if err != nil {
+				return err
+			}
+			if len(inputNodes) == 0 {
+				_, _ = fmt.Fprint(tw, "-\t-\t")
+				continue
+			}
+			out, err := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: assigner})
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t", nbColumns(out), nbCrossings(out))
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,113 @@
+package git2graph
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 5 goes down its lane to 4 instead of crossing the lane waiting for 3 on its row
+func TestCompactLanes(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}},
+		{"id": "2", "parents": []string{"4"}},
+		{"id": "5", "parents": []string{"4"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}},
+	}
+	out, _ := buildTreeTestWithOptions(inputNodes, &Options{ColorGen: customColors, LaneAssigner: CompactLanes})
+	expectedColumns := []int{0, 0, 2, 1, 0}
+	expectedPaths := []map[string]PathTest{
+		{"2": {Points: []*PointTest{{0, 0, Pipe}, {0, 1, Pipe}}, colorIdx: 0},
+			"3": {Points: []*PointTest{{0, 0, Pipe}, {1, 0, Fork}, {1, 3, Pipe}}, colorIdx: 1}},
+		{"4": {Points: []*PointTest{{0, 1, Pipe}, {0, 4, Pipe}}, colorIdx: 0}},
+		{"4": {Points: []*PointTest{{2, 2, Pipe}, {2, 4, MergeBack}, {0, 4, Pipe}}, colorIdx: 2}},
+		{"4": {Points: []*PointTest{{1, 3, Pipe}, {0, 3, MergeTo}, {0, 4, Pipe}}, colorIdx: 0}},
+		{},
+	}
+	validateColumns(t, expectedColumns, out)
+	validatePaths(t, expectedPaths, out)
+	validateColors(t, expectedPaths, out)
+}
+
+// Every path goes from its child to its parent, and pages have the columns of the whole layout
+func TestCompactLanesCorpus(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		if len(inputNodes) < 2 {
+			continue
+		}
+		full, err := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: CompactLanes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		byID := make(map[string][]any)
+		for _, node := range full.Nodes {
+			byID[node.GetID()] = (*node)[gKey].([]any)
+		}
+		for _, node := range full.Nodes {
+			g := (*node)[gKey].([]any)
+			for i, points := range outPaths(node) {
+				x, y, _ := outPoint(points[0])
+				if x != g[1] || y != *g[0].(*int) {
+					t.Errorf("%s: path of %s starts at %d,%d", file, node.GetID(), x, y)
+				}
+				if parent, ok := byID[node.GetParents()[i]]; ok {
+					x, y, _ = outPoint(points[len(points)-1])
+					if x != parent[1] || y != *parent[0].(*int) {
+						t.Errorf("%s: path of %s ends at %d,%d", file, node.GetID(), x, y)
+					}
+				}
+			}
+		}
+
+		inputNodes, _ = GetInputNodesFromFile(file)
+		from := inputNodes[0].GetID()
+		page, _ := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: CompactLanes, From: from, Limit: 3})
+		for _, node := range page.Nodes {
+			assertEq(t, byID[node.GetID()][1], (*node)[gKey].([]any)[1])
+		}
+	}
+}
+
+// Moving and swapping the lanes of the compact layout crosses fewer paths than the greedy layout
+func TestCompactLanesCrossings(t *testing.T) {
+	layout := func(file string, assigner LaneAssigner) *Out {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		out, err := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: assigner})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	assertEq(t, 19, nbCrossings(layout("../data/test_029.json", GreedyLanes)))
+	assertEq(t, 5, nbCrossings(layout("../data/test_029.json", CompactLanes)))
+	assertEq(t, 9, nbColumns(layout("../data/test_029.json", CompactLanes)))
+
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	greedy, compact := 0, 0
+	for _, file := range files {
+		if inputNodes, _ := GetInputNodesFromFile(file); len(inputNodes) == 0 {
+			continue
+		}
+		greedy += nbCrossings(layout(file, GreedyLanes))
+		compact += nbCrossings(layout(file, CompactLanes))
+	}
+	if compact >= greedy {
+		t.Errorf("compact layout crosses %d paths, greedy %d", compact, greedy)
+	}
+}
+
+func TestWriteLanesReport(t *testing.T) {
+	files, _ := filepath.Glob("../data/test_*[0-9].json")
+	var buf bytes.Buffer
+	if err := WriteLanesReport(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assertEq(t, len(files)+1, len(lines))
+	assertEq(t, "file  greedy columns  greedy crossings  compact columns  compact crossings", strings.TrimSpace(lines[0]))
+}
@@ -0,0 +1,111 @@
+package git2graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// Number of rows laid out in between two checks of the context
+const cancelCheckInterval = 256
+
+// Options configures how a graph is laid out
+type Options struct {
+	From               string          // Id of the node right above the page, empty for the first page
+	Limit              int             // Number of nodes in the page, <= 0 for no limit
+	ColorGen           IColorGenerator // Defaults to a CycleColorGen over DefaultColors
+	Cache              *LayoutCache    // Optional lane-state snapshots cache
+	MaxNodes           int             // Maximum number of input nodes, <= 0 for no limit
+	MaxColumns         int             // Maximum number of simultaneous columns, <= 0 for no limit
+	MaxPathPoints      int             // Maximum number of points of the paths, counted as the rows are laid out (those above the page too), <= 0 for no limit
+	MaxLanes           int             // Maximum number of visible columns, the others are collapsed in the last one, <= 0 for no limit
+	PriorityRefs       []string        // Ref patterns (path.Match syntax) whose first-parent chains are pinned to the leftmost columns, in order
+	Orientation        Orientation     // TopDown (default), BottomUp or LeftToRight
+	TimeSpacing        *TimeSpacing    // Optional time-proportional spacing of the rows
+	FoldLinearRuns     int             // Runs of at least FoldLinearRuns linear commits are folded into one "N commits" node, <= 0 to disable
+	LaneAssigner       LaneAssigner    // Layout strategy, defaults to GreedyLanes
+	FoldMergedBranches bool            // Fold each merged branch into one node attached to its merge
+}
+
+func (o *Options) limit() int {
+	return utils.Ternary(o.Limit > 0, o.Limit, -1)
+}
+
+func (o *Options) laneAssigner() LaneAssigner {
+	if o.LaneAssigner == nil {
+		return GreedyLanes
+	}
+	return o.LaneAssigner
+}
+
+func (o *Options) colorGen() IColorGenerator {
+	if o.ColorGen == nil {
+		return NewCycleColorGen(DefaultColors)
+	}
+	return o.ColorGen
+}
+
+// LimitKind identifies which resource limit was exceeded
+type LimitKind int
+
+const (
+	NodesLimit LimitKind = iota
+	ColumnsLimit
+	PathPointsLimit
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case NodesLimit:
+		return "nodes"
+	case ColumnsLimit:
+		return "columns"
+	case PathPointsLimit:
+		return "path points"
+	}
+	return "unknown"
+}
+
+// LimitError is returned when a layout exceeds one of the resource limits of Options
+type LimitError struct {
+	Kind  LimitKind
+	Max   int
+	Value int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("layout exceeds the maximum number of %s (%d > %d)", e.Kind, e.Value, e.Max)
+}
+
+func checkLimit(kind LimitKind, max, value int) error {
+	if max > 0 && value > max {
+		return &LimitError{Kind: kind, Max: max, Value: value}
+	}
+	return nil
+}
+
+// LayoutContext gets the necessary information to render the graph for the page described by opts.
+// The layout stops with ctx.Err() as soon as ctx is done, or with a *LimitError when a limit of opts is exceeded.
+func LayoutContext(ctx context.Context, inputNodes []*Node, opts *Options) (*Out, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	out, err := buildTree(ctx, inputNodes, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	return out, opts.Cache.Save()
+}
+
+// LayoutRowsContext is LayoutContext for the rows output
+func LayoutRowsContext(ctx context.Context, inputNodes []*Node, opts *Options) (*Out, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	out, err := buildTreeRows(ctx, inputNodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return out, opts.Cache.Save()
+}
@@ -0,0 +1,126 @@
+package git2graph
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayoutContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LayoutContext(ctx, generateLinearNodes(1000), nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := LayoutRowsContext(ctx, generateLinearNodes(1000), nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLayoutContextLimits(t *testing.T) {
+	tests := []struct {
+		opts *Options
+		kind LimitKind
+	}{
+		{&Options{MaxNodes: 10}, NodesLimit},
+		{&Options{MaxColumns: 2}, ColumnsLimit},
+		{&Options{MaxPathPoints: 50}, PathPointsLimit},
+	}
+	for _, tt := range tests {
+		_, err := LayoutContext(context.Background(), generateWideNodes(100, 5), tt.opts)
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Kind != tt.kind {
+			t.Errorf("expected %s limit error, got %v", tt.kind, err)
+		}
+	}
+	opts := &Options{MaxNodes: 100, MaxColumns: 5, MaxPathPoints: 1000}
+	if _, err := LayoutContext(context.Background(), generateWideNodes(100, 5), opts); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err := LayoutRowsContext(context.Background(), generateWideNodes(100, 5), &Options{MaxPathPoints: 50})
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Kind != PathPointsLimit {
+		t.Errorf("expected path points limit error, got %v", err)
+	}
+}
+
+// The points are counted as the rows are laid out, the layout stops once there are more than the limit
+func TestMaxPathPointsCorpus(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		for _, assigner := range LaneAssigners() {
+			inputNodes, _ := GetInputNodesFromFile(file)
+			out, err := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: assigner})
+			if err != nil {
+				t.Fatal(err)
+			}
+			nbPoints := 0
+			for _, node := range out.Nodes {
+				for _, points := range outPaths(node) {
+					nbPoints += len(points)
+				}
+			}
+			if nbPoints == 0 {
+				continue
+			}
+			inputNodes, _ = GetInputNodesFromFile(file)
+			if _, err := LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: assigner, MaxPathPoints: nbPoints}); err != nil {
+				t.Errorf("%s %s: unexpected error %v", file, assigner.Name(), err)
+			}
+			inputNodes, _ = GetInputNodesFromFile(file)
+			_, err = LayoutContext(context.Background(), inputNodes, &Options{LaneAssigner: assigner, MaxPathPoints: nbPoints - 1})
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Kind != PathPointsLimit {
+				t.Errorf("%s %s: expected path points limit error at %d points, got %v", file, assigner.Name(), nbPoints, err)
+			}
+		}
+	}
+}
+
+func TestMaxLanes(t *testing.T) {
+	inputNodes, _ := GetInputNodesFromFile("../data/test_029.json")
+	out, err := LayoutContext(context.Background(), inputNodes, &Options{MaxLanes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbCollapsed := 0
+	for _, node := range out.Nodes {
+		g := (*node)[gKey].([]any)
+		if column := g[1].(int); column > 3 {
+			t.Errorf("ID: %s, column %d is beyond the overflow lane", node.GetID(), column)
+		}
+		for _, p := range g[3].([]any) {
+			path := p.([]any)
+			if len(path) == 3 && path[2] == true {
+				nbCollapsed++
+			}
+			points := path[1].([][]any)
+			for i, point := range points {
+				if point[0].(int) > 3 {
+					t.Errorf("ID: %s, point %v is beyond the overflow lane", node.GetID(), point)
+				}
+				if i > 0 && point[0] == points[i-1][0] && point[1] == points[i-1][1] {
+					t.Errorf("ID: %s, duplicated point %v", node.GetID(), point)
+				}
+			}
+		}
+	}
+	if nbCollapsed == 0 {
+		t.Error("expected collapsed paths")
+	}
+	inputNodes, _ = GetInputNodesFromFile("../data/test_029.json")
+	if _, err := LayoutRowsContext(context.Background(), inputNodes, &Options{MaxLanes: 4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxLanesWideEnough(t *testing.T) {
+	nodes1, _ := GetInputNodesFromFile("../data/test_029.json")
+	expected, _ := LayoutContext(context.Background(), nodes1, nil)
+	nodes2, _ := GetInputNodesFromFile("../data/test_029.json")
+	actual, _ := LayoutContext(context.Background(), nodes2, &Options{MaxLanes: 20})
+	if serializePage(t, expected) != serializePage(t, actual) {
+		t.Error("layout should not change when all the columns are visible")
+	}
+}
@@ -0,0 +1,227 @@
+package git2graph
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+)
+
+// LayoutMetrics measures the quality of a tree output, to catch layout regressions that are not bugs per se.
+// Rows are the rows of the nodes, partial paths are not measured.
+type LayoutMetrics struct {
+	MaxWidth           int     `json:"maxWidth"`           // Number of columns
+	AvgWidth           float64 `json:"avgWidth"`           // Average over the rows of the columns in use on the row
+	Crossings          int     `json:"crossings"`          // Number of times a path going across the columns crosses a path going down a column
+	LaneChanges        int     `json:"laneChanges"`        // Number of times a path moves to another column
+	LaneChangesPerPath float64 `json:"laneChangesPerPath"` // Average number of lane changes of the paths
+	PathLength         int     `json:"pathLength"`         // Total length of the paths, in columns and rows
+	ColorReuse         int     `json:"colorReuse"`         // Number of times a color is used again after all the paths of that color ended
+}
+
+// Metrics returns the quality metrics of a top-down tree output laid out without Options.TimeSpacing:
+// the rows of a time-spaced output are positions, which the widths and the path lengths do not measure in rows.
+func Metrics(out *Out) (m LayoutMetrics) {
+	hs, vs := outSegments(out)
+	m.MaxWidth = nbColumns(out)
+	m.Crossings = nbCrossings(out)
+
+	// Columns in use on each row: the nodes, the paths going down through the row, and the paths going across it
+	if len(out.Nodes) > 0 {
+		widths := make(map[int]int, len(out.Nodes))
+		for _, node := range out.Nodes {
+			row := outRow(node)
+			widths[row] = max(widths[row], outColumn(node)+1)
+		}
+		for _, v := range vs {
+			for row := v.y1; row <= v.y2; row++ {
+				if width, ok := widths[row]; ok {
+					widths[row] = max(width, v.x+1)
+				}
+			}
+		}
+		for _, h := range hs {
+			if width, ok := widths[h.y]; ok {
+				widths[h.y] = max(width, h.x2+1)
+			}
+		}
+		total := 0
+		for _, width := range widths {
+			total += width
+		}
+		m.AvgWidth = float64(total) / float64(len(widths))
+	}
+
+	for _, h := range hs {
+		m.PathLength += h.x2 - h.x1
+	}
+	for _, v := range vs {
+		m.PathLength += v.y2 - v.y1
+	}
+
+	// Rows spanned by the paths of each color, in paths order
+	type span struct{ y1, y2 int }
+	spans := make(map[string][]span)
+	nbPaths := 0
+	for _, node := range out.Nodes {
+		g := (*node)[gKey].([]any)
+		for i, points := range outPaths(node) {
+			nbPaths++
+			for j := 1; j < len(points); j++ {
+				x1, _, _ := outPoint(points[j-1])
+				x2, _, _ := outPoint(points[j])
+				if x1 != x2 {
+					m.LaneChanges++
+				}
+			}
+			_, y1, _ := outPoint(points[0])
+			_, y2, _ := outPoint(points[len(points)-1])
+			color := fmt.Sprint(g[3].([]any)[i].([]any)[0])
+			spans[color] = append(spans[color], span{min(y1, y2), max(y1, y2)})
+		}
+	}
+	if nbPaths > 0 {
+		m.LaneChangesPerPath = float64(m.LaneChanges) / float64(nbPaths)
+	}
+	for _, colorSpans := range spans {
+		slices.SortFunc(colorSpans, func(a, b span) int { return cmp.Compare(a.y1, b.y1) })
+		end := colorSpans[0].y2
+		for _, s := range colorSpans[1:] {
+			if s.y1 > end {
+				m.ColorReuse++
+			}
+			end = max(end, s.y2)
+		}
+	}
+	return m
+}
+
+// ErrMetricsTimeSpacing is returned by WriteMetricsReport for options with a time spacing, which Metrics does not measure
+var ErrMetricsTimeSpacing = errors.New("metrics of a time-spaced layout are not supported")
+
+// WriteMetricsReport lays out every file with opts, and writes their metrics, one file per line
+func WriteMetricsReport(w io.Writer, files []string, opts *Options) error {
+	if opts != nil && opts.TimeSpacing != nil {
+		return ErrMetricsTimeSpacing
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "file\tmax width\tavg width\tcrossings\tlane changes\tper path\tpath length\tcolor reuse\t")
+	for _, file := range files {
+		inputNodes, err := GetInputNodesFromFile(file)
+		if err != nil {
+			return err
+		}
+		out, err := LayoutContext(context.Background(), inputNodes, opts)
+		if err != nil {
+			return err
+		}
+		m := Metrics(out)
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%.2f\t%d\t%d\t%.2f\t%d\t%d\t\n", filepath.Base(file),
+			m.MaxWidth, m.AvgWidth, m.Crossings, m.LaneChanges, m.LaneChangesPerPath, m.PathLength, m.ColorReuse)
+	}
+	return tw.Flush()
+}
+
+// Parts of a path in between two consecutive points, with x1 <= x2 and y1 <= y2
+type hSegment struct{ y, x1, x2 int }
+type vSegment struct{ x, y1, y2 int }
+
+// Return the points of the paths of a node of a tree output
+func outPaths(node *Node) (paths [][][]any) {
+	g, _ := (*node)[gKey].([]any)
+	if len(g) < 4 {
+		return nil
+	}
+	for _, p := range g[3].([]any) {
+		paths = append(paths, p.([]any)[1].([][]any))
+	}
+	return paths
+}
+
+func outColumn(node *Node) int {
+	return (*node)[gKey].([]any)[1].(int)
+}
+
+func outRow(node *Node) int {
+	return *(*node)[gKey].([]any)[0].(*int)
+}
+
+// Return the column, row and type of a point of a tree output, the position of the row of a time-spaced output is truncated
+func outPoint(point []any) (x, y int, typ pointType) {
+	x = point[0].(int)
+	switch v := point[1].(type) {
+	case int:
+		y = v
+	case float64:
+		y = int(v)
+	}
+	return x, y, point[2].(pointType)
+}
+
+// Split the paths of a tree output in horizontal and vertical segments.
+// In between two points on different rows and columns, the path goes down first after a MergeBack, and across first otherwise.
+func outSegments(out *Out) (hs []hSegment, vs []vSegment) {
+	addH := func(y, x1, x2 int) {
+		if x1 != x2 {
+			hs = append(hs, hSegment{y, min(x1, x2), max(x1, x2)})
+		}
+	}
+	addV := func(x, y1, y2 int) {
+		if y1 != y2 {
+			vs = append(vs, vSegment{x, min(y1, y2), max(y1, y2)})
+		}
+	}
+	for _, node := range out.Nodes {
+		for _, points := range outPaths(node) {
+			for i := 1; i < len(points); i++ {
+				x1, y1, typ := outPoint(points[i-1])
+				x2, y2, _ := outPoint(points[i])
+				if typ == MergeBack {
+					addV(x1, y1, y2)
+					addH(y2, x1, x2)
+				} else {
+					addH(y1, x1, x2)
+					addV(x2, y1, y2)
+				}
+			}
+		}
+	}
+	return hs, vs
+}
+
+// Return the number of columns used by the nodes and the paths of a tree output
+func nbColumns(out *Out) (nb int) {
+	for _, node := range out.Nodes {
+		nb = max(nb, outColumn(node)+1)
+		for _, points := range outPaths(node) {
+			for _, point := range points {
+				x, _, _ := outPoint(point)
+				nb = max(nb, x+1)
+			}
+		}
+	}
+	return nb
+}
+
+// Return the number of times a path going across the columns crosses a path going down a column
+func nbCrossings(out *Out) (nb int) {
+	hs, vs := outSegments(out)
+	byColumn := make(map[int][]vSegment)
+	for _, v := range vs {
+		byColumn[v.x] = append(byColumn[v.x], v)
+	}
+	for _, h := range hs {
+		for x := h.x1 + 1; x < h.x2; x++ {
+			for _, v := range byColumn[x] {
+				if v.y1 < h.y && h.y < v.y2 {
+					nb++
+				}
+			}
+		}
+	}
+	return nb
+}
@@ -0,0 +1,43 @@
+package git2graph
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}},
+		{"id": "2", "parents": []string{"4"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}},
+		{"id": "5", "parents": []string{"6", "7"}},
+		{"id": "6", "parents": []string{"8"}},
+		{"id": "7", "parents": []string{"8"}},
+		{"id": "8", "parents": []string{}},
+	}
+	out, _ := LayoutContext(context.Background(), inputNodes, &Options{ColorGen: NewCycleColorGen([]string{"color1", "color2"})})
+	expected := LayoutMetrics{MaxWidth: 2, AvgWidth: 2, LaneChanges: 4, LaneChangesPerPath: 0.5, PathLength: 16, ColorReuse: 2}
+	assertEq(t, expected, Metrics(out))
+}
+
+func TestMetricsReportTimeSpacing(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteMetricsReport(&buf, []string{"../data/test_001.json"}, &Options{TimeSpacing: &TimeSpacing{}})
+	assertEq(t, ErrMetricsTimeSpacing, err)
+	assertEq(t, 0, buf.Len())
+}
+
+// Regenerate data/metrics.txt with "git2graph --metrics-report data/*[0-9].json > data/metrics.txt"
+func TestMetricsReport(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	var buf bytes.Buffer
+	if err := WriteMetricsReport(&buf, files, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := os.ReadFile("../data/metrics.txt")
+	assertEq(t, string(expected), buf.String())
+}
@@ -0,0 +1,199 @@
+package git2graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+const truncatedKey = "truncated" // Parents of a neighborhood node that are not its parents in the full history
+
+// Neighborhood lays out the subgraph around the commit id: its descendants up to up generations above it,
+// its ancestors up to down generations below it, and the merge bases of the merges among them.
+// See NeighborhoodNodes for the parents of the subgraph.
+func Neighborhood(inputNodes []*Node, id string, up, down int) (*Out, error) {
+	nodes, err := NeighborhoodNodes(inputNodes, id, up, down)
+	if err != nil {
+		return nil, err
+	}
+	return LayoutContext(context.Background(), nodes, nil)
+}
+
+// NeighborhoodNodes returns copies of the nodes of the subgraph of Neighborhood, in the order of inputNodes.
+// A parent outside the subgraph is replaced by its nearest ancestors in the subgraph, which are listed in the truncated property
+// since the edges skip commits. Parents with no ancestor in the subgraph are kept, the layout marks them as boundaries.
+func NeighborhoodNodes(inputNodes []*Node, id string, up, down int) ([]*Node, error) {
+	rows := make(map[string]int, len(inputNodes))
+	children := make(map[string][]string)
+	for row, node := range inputNodes {
+		rows[node.GetID()] = row
+		for _, parent := range node.GetParents() {
+			children[parent] = append(children[parent], node.GetID())
+		}
+	}
+	if _, ok := rows[id]; !ok {
+		return nil, fmt.Errorf("commit %s not found", id)
+	}
+	parentsOf := func(id string) []string {
+		if row, ok := rows[id]; ok {
+			return inputNodes[row].GetParents()
+		}
+		return nil
+	}
+	childrenOf := func(id string) []string { return children[id] }
+
+	inSubgraph := make(map[string]bool)
+	maps.Copy(inSubgraph, walkGenerations(id, max(up, 0), childrenOf))
+	maps.Copy(inSubgraph, walkGenerations(id, max(down, 0), parentsOf))
+	var merges []string
+	for nodeID := range inSubgraph {
+		if len(parentsOf(nodeID)) > 1 {
+			merges = append(merges, nodeID)
+		}
+	}
+	for _, merge := range merges {
+		for _, base := range mergeBases(parentsOf(merge), inputNodes, rows) {
+			inSubgraph[base] = true
+		}
+	}
+
+	// The ancestors of a parent outside the subgraph are below it, no need to look past the last row of the subgraph
+	lastRow := 0
+	for nodeID := range inSubgraph {
+		lastRow = max(lastRow, rows[nodeID])
+	}
+	nearestInSubgraph := func(parent string) (nearest []string) {
+		visited := map[string]bool{parent: true}
+		for queue := []string{parent}; len(queue) > 0; queue = queue[1:] {
+			row, ok := rows[queue[0]]
+			if !ok || row > lastRow {
+				continue
+			}
+			for _, ancestor := range parentsOf(queue[0]) {
+				if visited[ancestor] {
+					continue
+				}
+				visited[ancestor] = true
+				if inSubgraph[ancestor] {
+					nearest = append(nearest, ancestor)
+				} else {
+					queue = append(queue, ancestor)
+				}
+			}
+		}
+		return nearest
+	}
+
+	var nodes []*Node
+	for _, inputNode := range inputNodes {
+		if !inSubgraph[inputNode.GetID()] {
+			continue
+		}
+		node := maps.Clone(*inputNode)
+		parents, truncated := make([]string, 0), make([]string, 0)
+		for _, parent := range inputNode.GetParents() {
+			if _, ok := rows[parent]; inSubgraph[parent] || !ok {
+				parents = append(parents, parent)
+				continue
+			}
+			nearest := nearestInSubgraph(parent)
+			if len(nearest) == 0 {
+				parents = append(parents, parent)
+			}
+			for _, ancestor := range nearest {
+				if !slices.Contains(parents, ancestor) {
+					parents = append(parents, ancestor)
+					truncated = append(truncated, ancestor)
+				}
+			}
+		}
+		node[parentsKey] = parents
+		delete(node, gKey)
+		delete(node, truncatedKey)
+		if len(truncated) > 0 {
+			node[truncatedKey] = truncated
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes, nil
+}
+
+// Return the ids reached from id in at most n steps of next (no limit if n < 0), id included
+func walkGenerations(id string, n int, next func(string) []string) map[string]bool {
+	reached := map[string]bool{id: true}
+	generation := []string{id}
+	for ; n != 0 && len(generation) > 0; n-- {
+		var nextGeneration []string
+		for _, nodeID := range generation {
+			for _, other := range next(nodeID) {
+				if !reached[other] {
+					reached[other] = true
+					nextGeneration = append(nextGeneration, other)
+				}
+			}
+		}
+		generation = nextGeneration
+	}
+	return reached
+}
+
+// Return the best common ancestors of the commits: the common ancestors that no other common ancestor descends from.
+// Like git merge-base, the ancestors are walked down the rows, which have the children above their parents,
+// and the walk stops once only ancestors of common ancestors are left, so it does not go far past the merge bases.
+func mergeBases(ids []string, inputNodes []*Node, rows map[string]int) (bases []string) {
+	reachedFrom := make(map[string][]bool) // Commits of ids each reached commit descends from
+	stale := make(map[string]bool)         // Reached commits that descend from a common ancestor
+	walked := -1                           // Last walked row
+	pending := 0                           // Reached commits of the rows not walked yet and not stale
+	isPending := func(id string) bool {
+		row, ok := rows[id]
+		return ok && row > walked && reachedFrom[id] != nil && !stale[id]
+	}
+	reach := func(id string, from []bool, fromStale bool) {
+		wasPending := isPending(id)
+		if reachedFrom[id] == nil {
+			reachedFrom[id] = make([]bool, len(ids))
+		}
+		for i := range from {
+			reachedFrom[id][i] = reachedFrom[id][i] || from[i]
+		}
+		stale[id] = stale[id] || fromStale
+		if isPending(id) != wasPending {
+			pending += utils.Ternary(wasPending, -1, 1)
+		}
+	}
+	start := len(inputNodes)
+	for i, id := range ids {
+		from := make([]bool, len(ids))
+		from[i] = true
+		reach(id, from, false)
+		if row, ok := rows[id]; ok {
+			start = min(start, row)
+		}
+	}
+	for row := start; row < len(inputNodes) && pending > 0; row++ {
+		id := inputNodes[row].GetID()
+		if isPending(id) {
+			pending--
+		}
+		walked = row
+		if reachedFrom[id] == nil {
+			continue
+		}
+		common := !slices.Contains(reachedFrom[id], false)
+		for _, parent := range inputNodes[row].GetParents() {
+			reach(parent, reachedFrom[id], stale[id] || common)
+		}
+	}
+	// Parents missing from inputNodes are not walked, they are below the last row
+	for id, from := range reachedFrom {
+		if !stale[id] && !slices.Contains(from, false) {
+			bases = append(bases, id)
+		}
+	}
+	slices.Sort(bases)
+	return bases
+}
@@ -0,0 +1,91 @@
+package git2graph
+
+import (
+	"strings"
+	"testing"
+)
+
+// main: top, m3, m2, m1, m0, root, and a feature branch f1, f2, f3 forked from m0 and merged by m3
+func neighborhoodTestNodes() []*Node {
+	return []*Node{
+		{idKey: "top", parentsKey: []string{"m3"}},
+		{idKey: "m3", parentsKey: []string{"m2", "f3"}},
+		{idKey: "f3", parentsKey: []string{"f2"}},
+		{idKey: "m2", parentsKey: []string{"m1"}},
+		{idKey: "f2", parentsKey: []string{"f1"}},
+		{idKey: "m1", parentsKey: []string{"m0"}},
+		{idKey: "f1", parentsKey: []string{"m0"}},
+		{idKey: "m0", parentsKey: []string{"root"}},
+		{idKey: "root", parentsKey: []string{}},
+	}
+}
+
+func TestNeighborhoodNodes(t *testing.T) {
+	inputNodes := neighborhoodTestNodes()
+	nodes, err := NeighborhoodNodes(inputNodes, "m3", 1, 1)
+	assertEq(t, nil, err)
+	var actual []string
+	for _, node := range nodes {
+		line := node.GetID() + ":" + strings.Join(node.GetParents(), ",")
+		if truncated, ok := (*node)[truncatedKey].([]string); ok {
+			line += " truncated:" + strings.Join(truncated, ",")
+		}
+		actual = append(actual, line)
+	}
+	// m0 is the merge base of m3, the edges to it skip commits
+	assertEq(t, "top:m3|m3:m2,f3|f3:m0 truncated:m0|m2:m0 truncated:m0|m0:root", strings.Join(actual, "|"))
+	// The input is not modified
+	assertEq(t, "f2", inputNodes[2].GetParents()[0])
+	_, hasTruncated := (*inputNodes[2])[truncatedKey]
+	assertEq(t, false, hasTruncated)
+
+	nodes, err = NeighborhoodNodes(inputNodes, "f2", 0, 0)
+	assertEq(t, nil, err)
+	assertEq(t, 1, len(nodes))
+	assertEq(t, "f1", nodes[0].GetParents()[0])
+
+	_, err = NeighborhoodNodes(inputNodes, "unknown", 1, 1)
+	assertEq(t, "commit unknown not found", err.Error())
+}
+
+func TestNeighborhood(t *testing.T) {
+	out, err := Neighborhood(neighborhoodTestNodes(), "m3", 1, 1)
+	assertEq(t, nil, err)
+	assertEq(t, 5, len(out.Nodes))
+	assertEq(t, 0, len(Check(out)))
+	// The parent of the merge base is outside of the subgraph
+	assertBoundaries(t, out.Nodes[4], Boundary{ID: "root", Lane: 0})
+}
+
+// Criss-cross merges have two merge bases, b1 and b2 for a, and r is the merge base of x and y
+func TestMergeBases(t *testing.T) {
+	inputNodes := []*Node{
+		{idKey: "a", parentsKey: []string{"x", "y"}},
+		{idKey: "x", parentsKey: []string{"b1", "b2"}},
+		{idKey: "y", parentsKey: []string{"b2", "b1"}},
+		{idKey: "b1", parentsKey: []string{"r"}},
+		{idKey: "b2", parentsKey: []string{"r"}},
+		{idKey: "r", parentsKey: []string{}},
+	}
+	nodes, err := NeighborhoodNodes(inputNodes, "a", 0, 1)
+	assertEq(t, nil, err)
+	var ids []string
+	for _, node := range nodes {
+		ids = append(ids, node.GetID())
+	}
+	assertEq(t, "a,x,y,b1,b2,r", strings.Join(ids, ","))
+}
+
+// A parent that is an ancestor of the other one is the merge base, and parents missing from the input can be merge bases
+func TestMergeBasesAncestorAndMissing(t *testing.T) {
+	inputNodes := []*Node{
+		{idKey: "a", parentsKey: []string{"b", "c"}},
+		{idKey: "b", parentsKey: []string{"c", "x"}},
+		{idKey: "c", parentsKey: []string{"x"}},
+		{idKey: "d", parentsKey: []string{"x"}},
+	}
+	rows := map[string]int{"a": 0, "b": 1, "c": 2, "d": 3}
+	assertEq(t, "c", strings.Join(mergeBases([]string{"b", "c"}, inputNodes, rows), ","))
+	assertEq(t, "x", strings.Join(mergeBases([]string{"c", "d"}, inputNodes, rows), ","))
+	assertEq(t, "", strings.Join(mergeBases([]string{"c", "y"}, inputNodes, rows), ","))
+}
@@ -0,0 +1,108 @@
+package git2graph
+
+import (
+	"slices"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// Orientation is the direction in which the history is laid out
+type Orientation int
+
+const (
+	TopDown     Orientation = iota // Newest commits at the top (default)
+	BottomUp                       // Oldest commits at the top, the root is on the first row
+	LeftToRight                    // Timeline with the oldest commits on the left, points are (x: row, y: column), rows are columns
+)
+
+// Return either or not the oldest commits come first
+func (o Orientation) reversed() bool {
+	return o == BottomUp || o == LeftToRight
+}
+
+// Return the output coordinates of a point, its row along the timeline and its column across the lanes.
+// The types of the points read along the timeline, left to right for LeftToRight like top to bottom otherwise.
+func (o Orientation) coords(column int, row any) (any, any) {
+	if o == LeftToRight {
+		return row, column
+	}
+	return column, row
+}
+
+// Mirror the layout vertically, the row y becomes nbRows-1-y.
+// Paths are reversed so that their points still go from top to bottom, and their corners are retyped:
+// a corner going horizontally then down (Fork, MergeTo) becomes a corner going down then horizontally (MergeBack),
+// and the other way around.
+// Returns the nodes in their new rows order.
+func flipRows(nodes []*internalNode, partialPaths []*Path, nbRows int) []*internalNode {
+	mapRows(nodes, partialPaths, func(y int) int { return nbRows - 1 - y })
+	flipped := make(map[*Path]struct{})
+	flip := func(path *Path) {
+		if _, ok := flipped[path]; !ok {
+			flipped[path] = struct{}{}
+			flipPath(path)
+		}
+	}
+	for _, node := range nodes {
+		for _, path := range node.parentsPaths {
+			flip(path)
+		}
+	}
+	for _, path := range partialPaths {
+		flip(path)
+	}
+	out := slices.Clone(nodes)
+	slices.Reverse(out)
+	return out
+}
+
+func flipPath(path *Path) {
+	points := slices.Clone(path.Points)
+	slices.Reverse(points)
+	for i, point := range points {
+		var typ pointType
+		switch point.getType() {
+		case Pipe:
+			continue
+		case Fork, MergeTo:
+			typ = MergeBack
+		case MergeBack:
+			// The path now comes horizontally from the previous point, then goes down
+			typ = utils.Ternary(i > 0 && points[i-1].getX() > point.getX(), MergeTo, Fork)
+		}
+		points[i] = flipPoint(point, typ)
+	}
+	path.Points = points
+}
+
+// Points can be shared between paths, so a retyped point is a new point on the same row
+func flipPoint(point IPoint, typ pointType) IPoint {
+	switch p := point.(type) {
+	case *Point:
+		return newPoint(p.x, p.y, typ)
+	case *PointTest:
+		return &PointTest{x: p.x, y: p.y, typ: typ}
+	}
+	return point
+}
+
+// Mirror the rows output vertically: rows are reversed, half lines swap ends,
+// and a ForkLine (horizontal then down) becomes a MergeBackLine (down then horizontal) and the other way around.
+func flipRowsLines(rows []*row) {
+	slices.Reverse(rows)
+	for _, r := range rows {
+		for i, line := range r.lines {
+			switch line.typ {
+			case BottomHalfLine:
+				line.typ = TopHalfLine
+			case TopHalfLine:
+				line.typ = BottomHalfLine
+			case ForkLine:
+				line.typ, line.x1, line.x2 = MergeBackLine, line.x2, line.x1
+			case MergeBackLine:
+				line.typ, line.x1, line.x2 = ForkLine, line.x2, line.x1
+			}
+			r.lines[i] = line
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package git2graph
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBottomUp(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}},
+		{"id": "2", "parents": []string{"4"}},
+		{"id": "3", "parents": []string{"4"}},
+		{"id": "4", "parents": []string{}},
+	}
+	out, _ := buildTreeTestWithOptions(inputNodes, &Options{ColorGen: customColors, Orientation: BottomUp})
+
+	expectedIDs := []string{"4", "3", "2", "1"}
+	for idx, node := range out {
+		assertEq(t, expectedIDs[idx], node.GetID())
+		assertEq(t, idx, *(*node)[gKey].([]any)[0].(*int))
+	}
+	expectedColumns := []int{0, 1, 0, 0}
+	expectedPaths := []map[string]PathTest{
+		{},
+		{"4": {Points: []*PointTest{{0, 0, Pipe}, {1, 0, Fork}, {1, 1, Pipe}}}},
+		{"4": {Points: []*PointTest{{0, 0, Pipe}, {0, 2, Pipe}}}},
+		{"2": {Points: []*PointTest{{0, 2, Pipe}, {0, 3, Pipe}}}, "3": {Points: []*PointTest{{1, 1, Pipe}, {1, 3, MergeBack}, {0, 3, Pipe}}}},
+	}
+	validateColumns(t, expectedColumns, out)
+	validatePaths(t, expectedPaths, out)
+}
+
+func TestBottomUpRows(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}},
+		{"id": "2", "parents": []string{"3"}},
+		{"id": "3", "parents": []string{}},
+	}
+	out, _ := LayoutRowsContext(context.Background(), inputNodes, &Options{ColorGen: customColors, Orientation: BottomUp})
+	by, _ := json.Marshal(out.Nodes)
+	expected := `[{"g":[0,"color1",[[0,0,0,"color1"],[0,0,0,"color1"],[0,1,3,"color2"]]],"id":"3","parents":[]},` +
+		`{"g":[0,"color1",[[0,0,1,"color1"],[0,0,0,"color1"],[1,1,2,"color2"],[1,1,0,"color2"],[0,0,0,"color1"]]],"id":"2","parents":["3"]},` +
+		`{"g":[0,"color1",[[0,0,1,"color1"],[1,0,4,"color2"]]],"id":"1","parents":["2","3"]}]`
+	assertEq(t, expected, string(by))
+}
+
+// Flipping twice gives back the top-down layout
+func TestBottomUpFlipTwice(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		if len(inputNodes) < 2 {
+			continue
+		}
+		for _, opts := range []*Options{{}, {From: inputNodes[0].GetID(), Limit: 3}} {
+			inputNodes, _ := GetInputNodesFromFile(file)
+			expectedNodes, partialPaths, _ := setColumns(context.Background(), inputNodes, opts)
+			if len(expectedNodes) == 0 {
+				continue
+			}
+			expected := serializeInternal(expectedNodes, partialPaths)
+			nodes := flipRows(expectedNodes, partialPaths, len(inputNodes))
+			nodes = flipRows(nodes, partialPaths, len(inputNodes))
+			if actual := serializeInternal(nodes, partialPaths); actual != expected {
+				t.Errorf("%s %+v:\n%s\n%s", file, opts, expected, actual)
+			}
+		}
+	}
+}
+
+// Left to right rows are the columns of the timeline, which read like the bottom-up rows
+func TestLeftToRightRows(t *testing.T) {
+	files, _ := filepath.Glob("../data/*[0-9].json")
+	for _, file := range files {
+		layout := func(orientation Orientation) string {
+			inputNodes, _ := GetInputNodesFromFile(file)
+			out, err := LayoutRowsContext(context.Background(), inputNodes, &Options{Orientation: orientation})
+			if err != nil {
+				t.Fatal(err)
+			}
+			by, _ := json.Marshal(out.Nodes)
+			return string(by)
+		}
+		if expected, actual := layout(BottomUp), layout(LeftToRight); actual != expected {
+			t.Errorf("%s:\n%s\n%s", file, expected, actual)
+		}
+	}
+}
+
+func serializeInternal(nodes []*internalNode, partialPaths []*Path) (s string) {
+	for _, node := range nodes {
+		s += node.id + ":" + strconv.Itoa(*node.idx) + ":"
+		for _, parent := range node.parents {
+			s += pprintPoints1(node.parentsPaths[parent.id].Points)
+		}
+		s += "\n"
+	}
+	for _, path := range partialPaths {
+		s += pprintPoints1(path.Points)
+	}
+	return s
+}
+
+func TestLeftToRightTimeSpacing(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2", "3"}, "timestamp": "7200"},
+		{"id": "2", "parents": []string{"4"}, "timestamp": "3600"},
+		{"id": "3", "parents": []string{"4"}, "timestamp": "1800"},
+		{"id": "4", "parents": []string{}, "timestamp": "0"},
+	}
+	opts := &Options{ColorGen: customColors, Orientation: LeftToRight, TimeSpacing: &TimeSpacing{Unit: time.Hour, MinGap: 0.25}}
+	out, _ := LayoutContext(context.Background(), inputNodes, opts)
+	expected := []string{
+		`[0,0,"color1",[],0]`,
+		`[1,1,"color2",[["color2",[[0,0,0],[0,1,2],[0.5,1,0]]]],0.5]`,
+		`[2,0,"color1",[["color1",[[0,0,0],[1,0,0]]]],1]`,
+		`[3,0,"color1",[["color1",[[1,0,0],[2,0,0]]],["color2",[[0.5,1,0],[2,1,1],[2,0,0]]]],2]`,
+	}
+	for idx, node := range out.Nodes {
+		by, _ := json.Marshal((*node)[gKey])
+		assertEq(t, expected[idx], string(by))
+	}
+
+	// Partial paths use the positions too
+	opts.From, opts.Limit = "1", 2
+	out, _ = LayoutContext(context.Background(), inputNodes, opts)
+	by, _ := json.Marshal(out.PartialPaths[1].Points)
+	assertEq(t, `[[0,1,0],[0.5,1,0]]`, string(by))
+
+	// Rows are the columns of the timeline, oldest first, with the lines going across the lanes
+	opts.From, opts.Limit = "", 0
+	out, err := LayoutRowsContext(context.Background(), inputNodes, opts)
+	assertEq(t, nil, err)
+	assertEq(t, LeftToRight, out.Orientation)
+	expected = []string{
+		`[0,"color1",[[0,0,0,"color1"],[0,0,0,"color1"],[0,1,3,"color2"]],0]`,
+		`[1,"color2",[[1,1,1,"color2"],[0,0,2,"color1"],[1,1,0,"color2"]],0.5]`,
+		`[0,"color1",[[0,0,1,"color1"],[0,0,0,"color1"],[1,1,2,"color2"],[1,1,0,"color2"],[0,0,0,"color1"]],1]`,
+		`[0,"color1",[[0,0,1,"color1"],[1,0,4,"color2"]],2]`,
+	}
+	for idx, node := range out.Nodes {
+		by, _ := json.Marshal((*node)[gKey])
+		assertEq(t, expected[idx], string(by))
+	}
+}
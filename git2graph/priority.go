@@ -0,0 +1,120 @@
+package git2graph
+
+import "strings"
+
+// Prefix of the ids of the virtual nodes that reserve the priority lanes.
+// One virtual node per priority lane is laid out above the first row, with the tip of the lane as first parent,
+// so that the greedy column assignment gives the leftmost columns to the priority lanes, in order.
+const priorityLaneIDPrefix = "\x00priority-lane:"
+
+func (n *internalNode) isPriorityLane() bool {
+	return strings.HasPrefix(n.id, priorityLaneIDPrefix)
+}
+
+// Return the tips of the refs matching the patterns, in patterns order then rows order.
+// A tip already on the first-parent chain of a previous tip is skipped, since its lane would be empty.
+func priorityTips(inputNodes []*Node, patterns []string) (tips []string) {
+	byID := make(map[string]*Node, len(inputNodes))
+	for _, node := range inputNodes {
+		byID[node.GetID()] = node
+	}
+	owned := make(map[string]bool)
+	for _, pattern := range patterns {
+		for _, node := range inputNodes {
+			if owned[node.GetID()] || !hasRefMatching(node, pattern) {
+				continue
+			}
+			tips = append(tips, node.GetID())
+			for cur := node; cur != nil && !owned[cur.GetID()]; {
+				owned[cur.GetID()] = true
+				parents := cur.GetParents()
+				if len(parents) == 0 {
+					break
+				}
+				cur = byID[parents[0]]
+			}
+		}
+	}
+	return tips
+}
+
+func hasRefMatching(node *Node, pattern string) bool {
+	for _, ref := range node.GetRefs() {
+		if refMatch(pattern, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prepend the virtual nodes reserving the priority lanes to the input
+func withPriorityLanes(inputNodes []*Node, patterns []string) ([]*Node, int) {
+	if len(patterns) == 0 {
+		return inputNodes, 0
+	}
+	tips := priorityTips(inputNodes, patterns)
+	if len(tips) == 0 {
+		return inputNodes, 0
+	}
+	out := make([]*Node, 0, len(tips)+len(inputNodes))
+	for _, tip := range tips {
+		out = append(out, &Node{idKey: priorityLaneIDPrefix + tip, parentsKey: []string{tip}})
+	}
+	return append(out, inputNodes...), len(tips)
+}
+
+// Remove the virtual nodes from the result, and shift all the rows up so that the first real row is 0 again
+func removePriorityLanes(nodes []*internalNode, partialPaths []*Path, nbVirtual int) []*internalNode {
+	if nbVirtual == 0 {
+		return nodes
+	}
+	out := make([]*internalNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.isPriorityLane() {
+			continue
+		}
+		children := make([]*internalNode, 0, len(node.children))
+		for _, child := range node.children {
+			if !child.isPriorityLane() {
+				children = append(children, child)
+			}
+		}
+		node.children = children
+		out = append(out, node)
+	}
+	shiftRows(out, partialPaths, -nbVirtual)
+	return out
+}
+
+// Move all the nodes, and the points of their paths, by delta rows
+func shiftRows(nodes []*internalNode, partialPaths []*Path, delta int) {
+	mapRows(nodes, partialPaths, func(y int) int { return y + delta })
+}
+
+// Replace the row of all the nodes, and of the points of their paths, by f(row).
+// Points share their row with the node they are on, so every row is mapped only once.
+func mapRows(nodes []*internalNode, partialPaths []*Path, f func(y int) int) {
+	mapped := make(map[*int]struct{})
+	mapRow := func(y *int) {
+		if _, ok := mapped[y]; !ok {
+			mapped[y] = struct{}{}
+			*y = f(*y)
+		}
+	}
+	mapPath := func(path *Path) {
+		for _, point := range path.Points {
+			if p, ok := point.(*Point); ok {
+				mapRow(p.y)
+			}
+		}
+	}
+	for _, node := range nodes {
+		mapRow(node.idx)
+		for _, path := range node.parentsPaths {
+			mapPath(path)
+		}
+	}
+	for _, path := range partialPaths {
+		mapPath(path)
+	}
+}
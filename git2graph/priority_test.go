@@ -0,0 +1,86 @@
+package git2graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseDecorate(t *testing.T) {
+	refs := parseDecorate(" (HEAD -> main, origin/main, tag: v1.0)")
+	expected := []string{"HEAD", "main", "origin/main", "v1.0"}
+	if len(refs) != len(expected) {
+		t.Fatalf("Expected: %v, Actual: %v", expected, refs)
+	}
+	for i := range refs {
+		assertEq(t, expected[i], refs[i])
+	}
+	assertEq(t, 0, len(parseDecorate("")))
+}
+
+// TestPriorityRefs main is pinned to column 0 and release/2 to column 1 even though the feature tip is newer.
+// release/1 is on the first-parent chain of main, so it does not get a lane of its own.
+func TestPriorityRefs(t *testing.T) {
+	inputNodes, _ := GetInputNodesFromFile("../data/test_042.json")
+	out, _ := buildTreeTestWithOptions(inputNodes, &Options{PriorityRefs: []string{"main", "release/*"}, ColorGen: customColors})
+
+	// Expected output
+	expectedColumns := []int{2, 1, 0, 2, 1, 0, 0, 1, 0}
+
+	expectedPaths := []map[string]PathTest{
+		{"3": {[]*PointTest{{2, 0, 0}, {2, 3, 0}}, 2}},
+		{"4": {[]*PointTest{{1, 1, 0}, {1, 4, 0}}, 1}},
+		{"5": {[]*PointTest{{0, 2, 0}, {0, 5, 0}}, 0}},
+		{"5": {[]*PointTest{{2, 3, 0}, {2, 5, 1}, {0, 5, 0}}, 2}},
+		{"6": {[]*PointTest{{1, 4, 0}, {1, 6, 1}, {0, 6, 0}}, 1}},
+		{
+			"6": {[]*PointTest{{0, 5, 0}, {0, 6, 0}}, 0},
+			"7": {[]*PointTest{{0, 5, 0}, {2, 5, 2}, {2, 6, 1}, {1, 6, 0}, {1, 7, 0}}, 3},
+		},
+		{"8": {[]*PointTest{{0, 6, 0}, {0, 8, 0}}, 0}},
+		{"8": {[]*PointTest{{1, 7, 0}, {1, 8, 1}, {0, 8, 0}}, 3}},
+	}
+
+	// Validation
+	validateColumns(t, expectedColumns, out)
+	validatePaths(t, expectedPaths, out)
+	validateColors(t, expectedPaths, out)
+}
+
+// Without priority refs, main is pushed to the right by the newer feature and release tips
+func TestPriorityRefsNone(t *testing.T) {
+	inputNodes, _ := GetInputNodesFromFile("../data/test_042.json")
+	out, _ := buildTreeTest(inputNodes, customColors, "", -1)
+	validateColumns(t, []int{0, 1, 2, 0, 1, 0, 0, 1, 0}, out)
+}
+
+// A page laid out with priority refs must have the same rows as the whole graph
+func TestPriorityRefsPaginated(t *testing.T) {
+	opts := &Options{PriorityRefs: []string{"main", "release/*"}}
+	inputNodes, _ := GetInputNodesFromFile("../data/test_042.json")
+	full, _ := LayoutContext(context.Background(), inputNodes, opts)
+	expectedColumns := make([]int, 0)
+	for _, node := range full.Nodes {
+		expectedColumns = append(expectedColumns, (*node)[gKey].([]any)[1].(int))
+	}
+	for _, page := range []struct {
+		from  string
+		limit int
+		first int
+	}{{"", 3, 0}, {"1", 3, 2}, {"4", 4, 5}} {
+		inputNodes, _ = GetInputNodesFromFile("../data/test_042.json")
+		out, _ := LayoutContext(context.Background(), inputNodes, &Options{From: page.from, Limit: page.limit, PriorityRefs: opts.PriorityRefs})
+		assertEq(t, page.limit, len(out.Nodes))
+		for i, node := range out.Nodes {
+			g := (*node)[gKey].([]any)
+			assertEq(t, page.first+i, *g[0].(*int))
+			assertEq(t, expectedColumns[page.first+i], g[1].(int))
+		}
+	}
+}
+
+func TestBranchColorGenPriorityLane(t *testing.T) {
+	inputNodes, _ := GetInputNodesFromFile("../data/test_042.json")
+	colorGen := NewBranchColorGen(DefaultColors)
+	out, _ := LayoutContext(context.Background(), inputNodes, &Options{PriorityRefs: []string{"main"}, ColorGen: colorGen})
+	assertEq(t, colorGen.GetLaneColor(Lane{Refs: []string{"main"}}), (*out.Nodes[2])[gKey].([]any)[2])
+}
@@ -0,0 +1,50 @@
+package git2graph
+
+import (
+	"path"
+	"strings"
+)
+
+const refsKey = "refs" // Ref names, alternative to decorate for json input
+
+// GetRefs returns the names of the refs pointing to the node.
+// They come from the "refs" property if any, or are parsed from the git decorate string:
+// " (HEAD -> main, origin/main, tag: v1.0)" gives ["HEAD", "main", "origin/main", "v1.0"]
+func (n *Node) GetRefs() (refs []string) {
+	switch v := (*n)[refsKey].(type) {
+	case []string:
+		return v
+	case []any:
+		for _, ref := range v {
+			if s, ok := ref.(string); ok {
+				refs = append(refs, s)
+			}
+		}
+		return refs
+	}
+	decorate, _ := (*n)[decorateKey].(string)
+	return parseDecorate(decorate)
+}
+
+func parseDecorate(decorate string) (refs []string) {
+	decorate = strings.TrimSpace(decorate)
+	decorate = strings.TrimSuffix(strings.TrimPrefix(decorate, "("), ")")
+	for _, ref := range strings.Split(decorate, ",") {
+		ref = strings.TrimSpace(ref)
+		ref = strings.TrimPrefix(ref, "tag: ")
+		if head, branch, ok := strings.Cut(ref, " -> "); ok {
+			refs = append(refs, head)
+			ref = branch
+		}
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Return either or not the ref name matches the pattern (path.Match syntax, "release/*")
+func refMatch(pattern, ref string) bool {
+	matched, err := path.Match(pattern, ref)
+	return err == nil && matched
+}
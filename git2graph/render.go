@@ -0,0 +1,195 @@
+package git2graph
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Geometry of the renderer, the same as the d3 renderer of tools/renderer
+const (
+	renderXGap = 11.0
+	renderYGap = 20.0
+	renderGap  = 2.0 / 5.0 * renderYGap // Vertical offset of the corners of the paths
+)
+
+// Render draws a tree output, in the orientation of its layout and with the positions of its time spacing:
+// the paths, then the nodes dots with a black outline, hollow for the virtual nodes.
+// The colors of the output are used, the theme gives the background, the sizes and the color of the uncolored lanes.
+func Render(out *Out, theme *Theme) *image.RGBA {
+	if theme == nil {
+		theme = &LightTheme
+	}
+	pad := math.Max(5, math.Ceil(theme.DotRadius+1))
+	maxColumn, maxRow := 0.0, 0.0
+	for _, node := range out.Nodes {
+		row, column := renderNodeCoords(node)
+		maxColumn, maxRow = max(maxColumn, column), max(maxRow, row)
+		for _, points := range outPaths(node) {
+			for _, point := range points {
+				row, column, _ := renderPointCoords(point, out.Orientation)
+				maxColumn, maxRow = max(maxColumn, column), max(maxRow, row)
+			}
+		}
+	}
+	width, height := renderPixel(maxRow, maxColumn, pad, out.Orientation)
+	img := image.NewRGBA(image.Rect(0, 0, int(width+pad)+1, int(height+pad)+1))
+	fill(img, parseHexColor(theme.Background, [3]uint8{255, 255, 255}))
+
+	defaultColor := parseHexColor(theme.Palette[0], [3]uint8{})
+	for _, node := range out.Nodes {
+		g := (*node)[gKey].([]any)
+		for i, points := range outPaths(node) {
+			clr := defaultColor
+			if s, ok := g[3].([]any)[i].([]any)[0].(string); ok {
+				clr = parseHexColor(s, defaultColor)
+			}
+			for j := 1; j < len(points); j++ {
+				x1, y1 := renderPoint(points[j-1], pad, out.Orientation)
+				x2, y2 := renderPoint(points[j], pad, out.Orientation)
+				drawSegment(img, x1, y1, x2, y2, theme.StrokeWidth, clr)
+			}
+		}
+	}
+	for _, node := range out.Nodes {
+		clr := defaultColor
+		if s, ok := (*node)[gKey].([]any)[2].(string); ok {
+			clr = parseHexColor(s, defaultColor)
+		}
+		if node.IsVirtual() {
+			clr = parseHexColor(theme.Background, [3]uint8{255, 255, 255})
+		}
+		row, column := renderNodeCoords(node)
+		x, y := renderPixel(row, column, pad, out.Orientation)
+		drawDot(img, x, y, theme.DotRadius, clr)
+	}
+	return img
+}
+
+// RenderPNG writes the Render image of a tree output as a png
+func RenderPNG(w io.Writer, out *Out, theme *Theme) error {
+	return png.Encode(w, Render(out, theme))
+}
+
+// Return the position of a path point in the image, corners are moved toward the row they come from
+func renderPoint(point []any, pad float64, orientation Orientation) (float64, float64) {
+	row, column, typ := renderPointCoords(point, orientation)
+	switch typ {
+	case MergeBack:
+		row -= renderGap / renderYGap
+	case Fork, MergeTo:
+		row += renderGap / renderYGap
+	}
+	return renderPixel(row, column, pad, orientation)
+}
+
+// Return the position in the image of a row (along the timeline) and a column
+func renderPixel(row, column, pad float64, orientation Orientation) (x, y float64) {
+	x, y = pad+column*renderXGap, pad+row*renderYGap
+	if orientation == LeftToRight {
+		x, y = pad+row*renderYGap, pad+column*renderXGap
+	}
+	return x, y
+}
+
+// Return the row of a node, its position with time spacing, and its column
+func renderNodeCoords(node *Node) (row, column float64) {
+	g := (*node)[gKey].([]any)
+	row = float64(outRow(node))
+	if len(g) > 4 {
+		row = g[4].(float64)
+	}
+	return row, float64(outColumn(node))
+}
+
+// Return the row (or position with time spacing), the column and the type of a path point of a tree output
+func renderPointCoords(point []any, orientation Orientation) (row, column float64, typ pointType) {
+	column, row = renderCoord(point[0]), renderCoord(point[1])
+	if orientation == LeftToRight {
+		column, row = row, column
+	}
+	return row, column, point[2].(pointType)
+}
+
+func renderCoord(v any) float64 {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case *int:
+		return float64(*v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// Parse a "#RRGGBB" color, def if it is not one
+func parseHexColor(s string, def [3]uint8) [3]uint8 {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return def
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return def
+	}
+	return [3]uint8{uint8(v >> 16), uint8(v >> 8), uint8(v)}
+}
+
+// Fill the image with an opaque color
+func fill(img *image.RGBA, clr [3]uint8) {
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			copy(img.Pix[i:i+3], clr[:])
+			img.Pix[i+3] = 255
+		}
+	}
+}
+
+// Blend a color over the pixel x, y with the given coverage in [0, 1]
+func blend(img *image.RGBA, x, y int, clr [3]uint8, coverage float64) {
+	if coverage <= 0 || !(image.Point{X: x, Y: y}.In(img.Rect)) {
+		return
+	}
+	coverage = math.Min(coverage, 1)
+	i := img.PixOffset(x, y)
+	for c, v := range clr {
+		img.Pix[i+c] = uint8(math.Round(float64(img.Pix[i+c])*(1-coverage) + float64(v)*coverage))
+	}
+	img.Pix[i+3] = 255
+}
+
+// Draw an antialiased segment, the coverage of a pixel is the part of it closer than width/2 to the segment
+func drawSegment(img *image.RGBA, x1, y1, x2, y2, width float64, clr [3]uint8) {
+	half := width / 2
+	minX, maxX := int(math.Floor(math.Min(x1, x2)-half-1)), int(math.Ceil(math.Max(x1, x2)+half+1))
+	minY, maxY := int(math.Floor(math.Min(y1, y2)-half-1)), int(math.Ceil(math.Max(y1, y2)+half+1))
+	dx, dy := x2-x1, y2-y1
+	length2 := dx*dx + dy*dy
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			t := 0.0
+			if length2 > 0 {
+				t = math.Max(0, math.Min(1, ((float64(x)-x1)*dx+(float64(y)-y1)*dy)/length2))
+			}
+			d := math.Hypot(float64(x)-(x1+t*dx), float64(y)-(y1+t*dy))
+			blend(img, x, y, clr, half+0.5-d)
+		}
+	}
+}
+
+// Draw an antialiased dot of the given color with a black outline
+func drawDot(img *image.RGBA, cx, cy, radius float64, clr [3]uint8) {
+	outline := [3]uint8{}
+	for y := int(math.Floor(cy - radius - 2)); y <= int(math.Ceil(cy+radius+2)); y++ {
+		for x := int(math.Floor(cx - radius - 2)); x <= int(math.Ceil(cx+radius+2)); x++ {
+			d := math.Hypot(float64(x)-cx, float64(y)-cy)
+			blend(img, x, y, clr, radius+0.5-d)
+			blend(img, x, y, outline, 1-math.Abs(d-radius))
+		}
+	}
+}
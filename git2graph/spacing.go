@@ -0,0 +1,97 @@
+package git2graph
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/alaingilbert/git2graph/git2graph/internal/utils"
+)
+
+// GetTimestamp returns the commit unix timestamp, from a number or a string "timestamp" property
+func (n *Node) GetTimestamp() (int64, bool) {
+	switch v := (*n)[timestampKey].(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	case string:
+		ts, err := strconv.ParseInt(v, 10, 64)
+		return ts, err == nil
+	}
+	return 0, false
+}
+
+// TimeSpacing spaces the rows by the time elapsed in between the commits instead of evenly.
+// The position of each node is added to the output, and replaces the row of the points of the nodes paths
+// and of the partial paths.
+type TimeSpacing struct {
+	Unit    time.Duration // Elapsed time for a distance of 1, defaults to one hour
+	MinGap  float64       // Minimum distance in between two rows, defaults to 1
+	IdleGap float64       // Distances above IdleGap are compressed logarithmically, <= 0 for no compression
+}
+
+func (s *TimeSpacing) unit() float64 {
+	return utils.Ternary(s.Unit > 0, s.Unit, time.Hour).Seconds()
+}
+
+func (s *TimeSpacing) minGap() float64 {
+	return utils.Ternary(s.MinGap > 0, s.MinGap, 1)
+}
+
+// Return the distance in between two rows for an elapsed time
+func (s *TimeSpacing) gap(elapsed int64) float64 {
+	gap := float64(elapsed) / s.unit()
+	if s.IdleGap > 0 && gap > s.IdleGap {
+		gap = s.IdleGap + math.Log1p(gap-s.IdleGap)
+	}
+	return max(gap, s.minGap())
+}
+
+// Position along the timeline of each row of a page
+type rowPositions struct {
+	first     int
+	minGap    float64
+	positions []float64
+}
+
+// Return the positions of the rows of the nodes, nil if the rows are evenly spaced.
+// Nodes are in rows order, consecutive nodes missing a timestamp are MinGap apart.
+func newRowPositions(nodes []*internalNode, spacing *TimeSpacing) *rowPositions {
+	if spacing == nil || len(nodes) == 0 {
+		return nil
+	}
+	r := &rowPositions{first: *nodes[0].idx, minGap: spacing.minGap(), positions: make([]float64, len(nodes))}
+	for i := 1; i < len(nodes); i++ {
+		gap := r.minGap
+		ts1, ok1 := nodes[i-1].initialNode.GetTimestamp()
+		ts2, ok2 := nodes[i].initialNode.GetTimestamp()
+		if ok1 && ok2 {
+			gap = spacing.gap(max(ts1-ts2, ts2-ts1))
+		}
+		r.positions[i] = r.positions[i-1] + gap
+	}
+	return r
+}
+
+// Return the position of a row, rows outside the page are MinGap apart from its first and last rows
+func (r *rowPositions) at(row int) float64 {
+	i := row - r.first
+	if i < 0 {
+		return r.positions[0] + float64(i)*r.minGap
+	}
+	if last := len(r.positions) - 1; i > last {
+		return r.positions[last] + float64(i-last)*r.minGap
+	}
+	return r.positions[i]
+}
+
+// Return the output coordinate of a row
+func (r *rowPositions) coord(row int) any {
+	if r == nil {
+		return row
+	}
+	return r.at(row)
+}
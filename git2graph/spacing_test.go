@@ -0,0 +1,37 @@
+package git2graph
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTimeSpacing(t *testing.T) {
+	inputNodes := []*Node{
+		{"id": "1", "parents": []string{"2"}, "timestamp": "864000"}, // 10 days after 2
+		{"id": "2", "parents": []string{"3"}, "timestamp": "0"},      // Same time as 3
+		{"id": "3", "parents": []string{"4"}, "timestamp": "0"},
+		{"id": "4", "parents": []string{"5"}}, // Missing timestamp
+		{"id": "5", "parents": []string{}, "timestamp": 3600.0},
+	}
+	opts := &Options{TimeSpacing: &TimeSpacing{Unit: time.Hour, MinGap: 0.5, IdleGap: 24}}
+	out, _ := LayoutContext(context.Background(), inputNodes, opts)
+	expected := []float64{0, 24 + math.Log1p(240-24), 0, 0, 0}
+	expected[2] = expected[1] + 0.5 // Minimum gap
+	expected[3] = expected[2] + 0.5
+	expected[4] = expected[3] + 0.5
+	for idx, node := range out.Nodes {
+		g := (*node)[gKey].([]any)
+		assertEq(t, idx, *g[0].(*int)) // Row index is kept
+		assertEq(t, expected[idx], g[4].(float64))
+	}
+	// Points use the positions
+	points := (*out.Nodes[0])[gKey].([]any)[3].([]any)[0].([]any)[1].([][]any)
+	assertEq(t, expected[1], points[len(points)-1][1].(float64))
+
+	rows, _ := LayoutRowsContext(context.Background(), inputNodes, opts)
+	for idx, node := range rows.Nodes {
+		assertEq(t, expected[idx], (*node)[gKey].([]any)[3].(float64))
+	}
+}
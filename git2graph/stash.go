@@ -0,0 +1,43 @@
+package git2graph
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// IncludeStashes adds the stashes of the repository in dir to its nodes, which must have the commits shas as ids.
+// A stash is a single node right above the commit it was created on, its only parent:
+// the commits of its index and untracked files are hidden. Its decorate is "stash@{n}".
+// Stashes whose base commit is not in nodes are left out.
+func IncludeStashes(dir string, nodes []*Node) ([]*Node, error) {
+	cmd := exec.Command("git", "stash", "list", repoLogFormat, "--date=local")
+	cmd.Dir = dir
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return nodes, err
+	}
+	return insertStashes(nodes, parseRepoLog(string(outBytes))), nil
+}
+
+// Insert the stashes, newest first, right above their base commit
+func insertStashes(nodes, stashes []*Node) []*Node {
+	if len(stashes) == 0 {
+		return nodes
+	}
+	stashesByBase := make(map[string][]*Node)
+	for i, stash := range stashes {
+		parents := stash.GetParents()
+		if len(parents) == 0 {
+			continue
+		}
+		(*stash)[parentsKey] = parents[:1]
+		(*stash)[decorateKey] = fmt.Sprintf(" (stash@{%d})", i)
+		stashesByBase[parents[0]] = append(stashesByBase[parents[0]], stash)
+	}
+	withStashes := make([]*Node, 0, len(nodes)+len(stashes))
+	for _, node := range nodes {
+		withStashes = append(withStashes, stashesByBase[node.GetID()]...)
+		withStashes = append(withStashes, node)
+	}
+	return withStashes
+}
@@ -0,0 +1,91 @@
+package git2graph
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsertStashes(t *testing.T) {
+	nodes := []*Node{
+		{idKey: "c2", parentsKey: []string{"c1"}, decorateKey: " (HEAD -> main)"},
+		{idKey: "c1", parentsKey: []string{"c0"}},
+		{idKey: "c0", parentsKey: []string{}},
+	}
+	stashes := []*Node{
+		{idKey: "s0", parentsKey: []string{"c1", "i0", "u0"}},
+		{idKey: "s1", parentsKey: []string{"c1", "i1"}},
+		{idKey: "s2", parentsKey: []string{"gone", "i2"}},
+	}
+	nodes = insertStashes(nodes, stashes)
+	assertEq(t, 5, len(nodes))
+	for i, id := range []string{"c2", "s0", "s1", "c1", "c0"} {
+		assertEq(t, id, nodes[i].GetID())
+	}
+	assertEq(t, 1, len(nodes[1].GetParents()))
+	assertEq(t, "c1", nodes[1].GetParents()[0])
+	assertEq(t, "stash@{1}", nodes[2].GetRefs()[0])
+
+	// Stashes are side nodes, the first parent chain of HEAD stays in the first column
+	out, err := LayoutContext(context.Background(), nodes, nil)
+	assertEq(t, nil, err)
+	for i, column := range []int{0, 1, 2, 0, 0} {
+		assertEq(t, column, outColumn(out.Nodes[i]))
+	}
+	assertEq(t, 0, len(Check(out)))
+}
+
+// Run git in dir, with an identity so that it commits and stashes without a git config
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// The stash of a repository is a single node right above the commit it was created on
+func TestIncludeStashesRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	commit := func(content, subject string) {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", "file.txt")
+		runGit(t, dir, "commit", "-q", "-m", subject)
+	}
+	runGit(t, dir, "init", "-q", "-b", "main")
+	commit("0", "c0")
+	commit("1", "c1")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("stashed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "stash", "-q")
+	commit("2", "c2")
+
+	nodes, err := GetInputNodesFromRepo(dir, DefaultOrder, -1)
+	assertEq(t, nil, err)
+	assertEq(t, 3, len(nodes))
+	base := nodes[1].GetID()
+	nodes, err = IncludeStashes(dir, nodes)
+	assertEq(t, nil, err)
+	assertEq(t, 4, len(nodes))
+	stash := nodes[1]
+	assertEq(t, "c2", (*nodes[0])[subjectKey])
+	assertEq(t, 1, len(stash.GetParents()))
+	assertEq(t, base, stash.GetParents()[0])
+	assertEq(t, "stash@{0}", stash.GetRefs()[0])
+	assertEq(t, base, nodes[2].GetID())
+
+	out, err := LayoutContext(context.Background(), nodes, nil)
+	assertEq(t, nil, err)
+	assertEq(t, 0, outColumn(out.Nodes[0]))
+	assertEq(t, 1, outColumn(out.Nodes[1]))
+	assertEq(t, 0, len(Check(out)))
+}
@@ -0,0 +1,128 @@
+package git2graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Theme holds the lanes palette and the drawing settings used by the renderers
+type Theme struct {
+	Name        string   `json:"name" yaml:"name"`
+	Palette     []string `json:"palette" yaml:"palette"`         // Lanes colors
+	Background  string   `json:"background" yaml:"background"`   // Background color
+	DotRadius   float64  `json:"dotRadius" yaml:"dotRadius"`     // Radius of the nodes dots
+	StrokeWidth float64  `json:"strokeWidth" yaml:"strokeWidth"` // Width of the paths
+	Font        string   `json:"font" yaml:"font"`               // Css font of the labels
+}
+
+// Built-in themes
+var (
+	LightTheme = Theme{
+		Name:        "light",
+		Palette:     DefaultColors,
+		Background:  "#FFFFFF",
+		DotRadius:   4,
+		StrokeWidth: 2,
+		Font:        "12px sans-serif",
+	}
+	DarkTheme = Theme{
+		Name: "dark",
+		Palette: []string{
+			"#5aa1be",
+			"#c065b8",
+			"#c0ab5f",
+			"#59bc95",
+			"#7a63be",
+			"#c0615b",
+			"#73bb5e",
+			"#6ee585",
+			"#7088e8",
+			"#eb77a3",
+			"#c2e675",
+			"#6fdfe9",
+			"#d87de8",
+			"#eab774",
+			"#be82fb",
+			"#72d7fc",
+			"#adfb82",
+		},
+		Background:  "#1E1E1E",
+		DotRadius:   4,
+		StrokeWidth: 2,
+		Font:        "12px sans-serif",
+	}
+	HighContrastTheme = Theme{
+		Name:        "high-contrast",
+		Palette:     []string{"#FFFF00", "#00FFFF", "#FF00FF", "#00FF00", "#FF8000", "#FFFFFF", "#FF4040", "#4080FF"},
+		Background:  "#000000",
+		DotRadius:   5,
+		StrokeWidth: 3,
+		Font:        "bold 14px sans-serif",
+	}
+	// Okabe-Ito palette, distinguishable with the common color vision deficiencies
+	ColorblindSafeTheme = Theme{
+		Name:        "colorblind-safe",
+		Palette:     []string{"#0072B2", "#E69F00", "#009E73", "#CC79A7", "#56B4E9", "#D55E00", "#F0E442", "#000000"},
+		Background:  "#FFFFFF",
+		DotRadius:   4,
+		StrokeWidth: 2,
+		Font:        "12px sans-serif",
+	}
+)
+
+var builtinThemes = map[string]Theme{
+	LightTheme.Name:          LightTheme,
+	DarkTheme.Name:           DarkTheme,
+	HighContrastTheme.Name:   HighContrastTheme,
+	ColorblindSafeTheme.Name: ColorblindSafeTheme,
+}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() (names []string) {
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetTheme returns the built-in theme with the given name, or loads the theme file at that path
+func GetTheme(nameOrPath string) (*Theme, error) {
+	if theme, ok := builtinThemes[nameOrPath]; ok {
+		theme.Palette = append([]string(nil), theme.Palette...)
+		return &theme, nil
+	}
+	return LoadTheme(nameOrPath)
+}
+
+// LoadTheme loads a theme from a json or yaml (.yaml/.yml) file.
+// The settings missing from the file are the ones of the light theme.
+func LoadTheme(path string) (*Theme, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	theme := LightTheme
+	theme.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	theme.Palette = append([]string(nil), LightTheme.Palette...) // Decoded in place, it must not be DefaultColors
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(by, &theme)
+	default:
+		err = json.Unmarshal(by, &theme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	if len(theme.Palette) == 0 {
+		return nil, errors.New("invalid theme " + path + ": empty palette")
+	}
+	return &theme, nil
+}
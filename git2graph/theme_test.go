@@ -0,0 +1,53 @@
+package git2graph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := GetTheme(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, name, theme.Name)
+		if len(theme.Palette) == 0 || theme.Background == "" || theme.DotRadius <= 0 || theme.StrokeWidth <= 0 || theme.Font == "" {
+			t.Errorf("incomplete theme %+v", theme)
+		}
+	}
+	// Built-in themes cannot be modified through the returned copy
+	theme, _ := GetTheme("light")
+	theme.Palette[0] = "#000000"
+	assertEq(t, "#005EBE", DefaultColors[0])
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "mine.json")
+	_ = os.WriteFile(jsonPath, []byte(`{"palette": ["#111111", "#222222"], "background": "#333333"}`), 0o644)
+	yamlPath := filepath.Join(dir, "mine.yaml")
+	_ = os.WriteFile(yamlPath, []byte("palette:\n  - \"#111111\"\n  - \"#222222\"\nbackground: \"#333333\"\n"), 0o644)
+	for _, path := range []string{jsonPath, yamlPath} {
+		theme, err := GetTheme(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEq(t, "mine", theme.Name)
+		assertEq(t, 2, len(theme.Palette))
+		assertEq(t, "#222222", theme.Palette[1])
+		assertEq(t, "#333333", theme.Background)
+		assertEq(t, LightTheme.DotRadius, theme.DotRadius) // Missing settings come from the light theme
+	}
+	assertEq(t, "#005EBE", DefaultColors[0]) // Loading a palette does not overwrite the default one
+
+	emptyPath := filepath.Join(dir, "empty.json")
+	_ = os.WriteFile(emptyPath, []byte(`{"palette": []}`), 0o644)
+	if _, err := LoadTheme(emptyPath); err == nil {
+		t.Fatal("expected an error for an empty palette")
+	}
+	if _, err := GetTheme("no-such-theme"); err == nil {
+		t.Fatal("expected an error for an unknown theme")
+	}
+}
@@ -0,0 +1,90 @@
+package git2graph
+
+import (
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	virtualKey  = "virtual"  // True for the nodes that are not commits
+	stagedKey   = "staged"   // Number of files with staged changes
+	unstagedKey = "unstaged" // Number of files with unstaged changes, untracked files included
+)
+
+// UncommittedID is the id of the uncommitted changes node
+const UncommittedID = "uncommitted"
+
+// PrependUncommitted adds an "Uncommitted changes" node above the nodes of the repository in dir,
+// when its working tree or index is dirty. Its parent is HEAD, so that it is laid out in the lane of HEAD,
+// and it has the virtual, staged and unstaged properties.
+func PrependUncommitted(dir string, nodes []*Node) ([]*Node, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = dir
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return nodes, err
+	}
+	staged, unstaged := parseStatus(string(outBytes))
+	if staged == 0 && unstaged == 0 {
+		return nodes, nil
+	}
+	parents := []string{}
+	if head := headID(nodes); head != "" {
+		parents = append(parents, head)
+	} else {
+		// HEAD was not loaded, or the repository has no commits yet
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = dir
+		if outBytes, err := cmd.Output(); err == nil {
+			parents = append(parents, strings.TrimSpace(string(outBytes)))
+		}
+	}
+	return append([]*Node{newUncommittedNode(parents, staged, unstaged)}, nodes...), nil
+}
+
+func newUncommittedNode(parents []string, staged, unstaged int) *Node {
+	return &Node{
+		idKey:        UncommittedID,
+		parentsKey:   parents,
+		subjectKey:   "Uncommitted changes",
+		timestampKey: strconv.FormatInt(time.Now().Unix(), 10),
+		virtualKey:   true,
+		stagedKey:    staged,
+		unstagedKey:  unstaged,
+	}
+}
+
+// Return the number of files with staged and unstaged changes of a "git status --porcelain" output
+func parseStatus(status string) (staged, unstaged int) {
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) < 2 || line[:2] == "!!" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '?' {
+			staged++
+		}
+		if line[1] != ' ' {
+			unstaged++
+		}
+	}
+	return staged, unstaged
+}
+
+// Return the id of the node HEAD points to, empty if none does
+func headID(nodes []*Node) string {
+	for _, node := range nodes {
+		if slices.Contains(node.GetRefs(), "HEAD") {
+			return node.GetID()
+		}
+	}
+	return ""
+}
+
+// IsVirtual returns either or not the node is a pseudo-commit, like the uncommitted changes node
+func (n *Node) IsVirtual() bool {
+	virtual, _ := (*n)[virtualKey].(bool)
+	return virtual
+}
@@ -0,0 +1,53 @@
+package git2graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	staged, unstaged := parseStatus("M  staged.go\n M unstaged.go\nMM both.go\nA  added.go\n?? untracked.go\n!! ignored.go\n")
+	assertEq(t, 3, staged)
+	assertEq(t, 3, unstaged)
+	staged, unstaged = parseStatus("")
+	assertEq(t, 0, staged)
+	assertEq(t, 0, unstaged)
+}
+
+// The uncommitted changes node is in the lane of HEAD, even when HEAD is not the first commit
+func TestUncommittedLane(t *testing.T) {
+	inputNodes := []*Node{
+		{idKey: "a", parentsKey: []string{"c"}, decorateKey: " (main)"},
+		{idKey: "b", parentsKey: []string{"c"}, decorateKey: " (HEAD -> feature)"},
+		{idKey: "c", parentsKey: []string{}},
+	}
+	assertEq(t, "b", headID(inputNodes))
+	inputNodes = append([]*Node{newUncommittedNode([]string{headID(inputNodes)}, 1, 2)}, inputNodes...)
+	out, err := LayoutContext(context.Background(), inputNodes, nil)
+	assertEq(t, nil, err)
+	uncommitted, head := out.Nodes[0], out.Nodes[2]
+	assertEq(t, UncommittedID, uncommitted.GetID())
+	assertEq(t, true, uncommitted.IsVirtual())
+	assertEq(t, false, head.IsVirtual())
+	assertEq(t, outColumn(head), outColumn(uncommitted))
+	assertEq(t, (*uncommitted)[gKey].([]any)[2], (*head)[gKey].([]any)[2])
+	for _, points := range outPaths(uncommitted) {
+		for _, point := range points {
+			x, _, _ := outPoint(point)
+			assertEq(t, outColumn(head), x)
+		}
+	}
+	assertEq(t, 0, len(Check(out)))
+}
+
+// With sequential ids, the uncommitted changes node keeps its id and its parent HEAD is renamed, even when HEAD was not loaded
+func TestUncommittedSequentialIDs(t *testing.T) {
+	nodes := SequentialIDs([]*Node{
+		newUncommittedNode([]string{"c0ffee"}, 1, 0),
+		{idKey: "b", parentsKey: []string{"a"}},
+	})
+	assertEq(t, UncommittedID, nodes[0].GetID())
+	assertEq(t, "1", nodes[0].GetParents()[0])
+	assertEq(t, "0", nodes[1].GetID())
+	assertEq(t, "2", nodes[1].GetParents()[0])
+}
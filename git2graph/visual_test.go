@@ -0,0 +1,196 @@
+package git2graph
+
+import (
+	"context"
+	"flag"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var visualDiffs = flag.String("visual-diffs", filepath.Join(os.TempDir(), "git2graph-visual-diffs"),
+	"directory of the diff images of the failing visual tests")
+
+const (
+	visualThreshold    = 0.1   // Perceptual difference of two pixels, in [0, 1], above which they differ
+	visualMaxDiffRatio = 0.001 // Part of the pixels of an image that may differ
+)
+
+// Return the golden image of a fixture, data/test_NNN.json is rendered as data/test_NNN.golden.png
+func visualGoldenFile(file string) string {
+	return strings.TrimSuffix(file, ".json") + ".golden.png"
+}
+
+// Return the YIQ perceptual difference of two pixels, in [0, 1]
+func pixelDelta(a, b [3]uint8) float64 {
+	dr, dg, db := float64(a[0])-float64(b[0]), float64(a[1])-float64(b[1]), float64(a[2])-float64(b[2])
+	y := dr*0.29889531 + dg*0.58662247 + db*0.11448223
+	i := dr*0.59597799 - dg*0.27417610 - db*0.32180189
+	q := dr*0.21147017 - dg*0.52261711 + db*0.31114694
+	return math.Sqrt((0.5053*y*y + 0.299*i*i + 0.1957*q*q) / 35215)
+}
+
+func pixelAt(img image.Image, x, y int) [3]uint8 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}
+}
+
+// Return the number of pixels that differ in between two images, and an image of the differences:
+// the expected image faded, with the pixels that differ in red. Pixels out of one of the images always differ.
+func visualDiff(expected, actual image.Image) (nbDiff int, diff *image.RGBA) {
+	bounds := expected.Bounds().Union(actual.Bounds())
+	diff = image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Point{X: x, Y: y}
+			if p.In(expected.Bounds()) && p.In(actual.Bounds()) {
+				e := pixelAt(expected, x, y)
+				if pixelDelta(e, pixelAt(actual, x, y)) <= visualThreshold {
+					luma := 0.29889531*float64(e[0]) + 0.58662247*float64(e[1]) + 0.11448223*float64(e[2])
+					gray := uint8(255 - (255-luma)*0.1)
+					fill(diff.SubImage(image.Rect(x, y, x+1, y+1)).(*image.RGBA), [3]uint8{gray, gray, gray})
+					continue
+				}
+			}
+			nbDiff++
+			fill(diff.SubImage(image.Rect(x, y, x+1, y+1)).(*image.RGBA), [3]uint8{255, 0, 0})
+		}
+	}
+	return nbDiff, diff
+}
+
+func writeImage(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func TestVisualDiff(t *testing.T) {
+	expected := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	fill(expected, [3]uint8{255, 255, 255})
+	actual := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	fill(actual, [3]uint8{250, 252, 255})
+	nbDiff, _ := visualDiff(expected, actual)
+	assertEq(t, 0, nbDiff)
+
+	fill(actual.SubImage(image.Rect(2, 3, 4, 4)).(*image.RGBA), [3]uint8{0, 94, 190})
+	nbDiff, diff := visualDiff(expected, actual)
+	assertEq(t, 2, nbDiff)
+	assertEq(t, [3]uint8{255, 0, 0}, pixelAt(diff, 3, 3))
+	assertEq(t, [3]uint8{255, 255, 255}, pixelAt(diff, 0, 0))
+
+	nbDiff, _ = visualDiff(expected, image.NewRGBA(image.Rect(0, 0, 10, 12)))
+	assertEq(t, 120, nbDiff)
+}
+
+// Every data/test_NNN.json fixture renders like its golden image, regenerate the goldens with -update
+func TestVisual(t *testing.T) {
+	files, _ := filepath.Glob("../data/test_[0-9][0-9][0-9].json")
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			inputNodes, _ := GetInputNodesFromFile(file)
+			opts, err := fixtureOptions(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := LayoutContext(context.Background(), inputNodes, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := Render(out, nil)
+			if *update {
+				if err := writeImage(visualGoldenFile(file), actual); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := readImage(visualGoldenFile(file))
+			if err != nil {
+				t.Fatalf("%v, regenerate the goldens with -update", err)
+			}
+			nbDiff, diff := visualDiff(expected, actual)
+			bounds := expected.Bounds().Union(actual.Bounds())
+			if float64(nbDiff) <= visualMaxDiffRatio*float64(bounds.Dx()*bounds.Dy()) {
+				return
+			}
+			name := strings.TrimSuffix(filepath.Base(file), ".json")
+			if err := os.MkdirAll(*visualDiffs, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			diffFile := filepath.Join(*visualDiffs, name+".diff.png")
+			if err := writeImage(diffFile, diff); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeImage(filepath.Join(*visualDiffs, name+".actual.png"), actual); err != nil {
+				t.Fatal(err)
+			}
+			t.Errorf("%d pixels differ from %s, see %s", nbDiff, visualGoldenFile(file), diffFile)
+		})
+	}
+}
+
+func transposeImage(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(bounds.Min.Y, bounds.Min.X, bounds.Max.Y, bounds.Max.X))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.Set(y, x, img.At(x, y))
+		}
+	}
+	return out
+}
+
+// A left-to-right layout renders like the bottom-up one, transposed
+func TestRenderLeftToRight(t *testing.T) {
+	files, _ := filepath.Glob("../data/test_[0-9][0-9][0-9].json")
+	for _, file := range files {
+		inputNodes, _ := GetInputNodesFromFile(file)
+		bottomUp, err := LayoutContext(context.Background(), inputNodes, &Options{Orientation: BottomUp})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := transposeImage(Render(bottomUp, nil))
+		inputNodes, _ = GetInputNodesFromFile(file)
+		leftToRight, err := LayoutContext(context.Background(), inputNodes, &Options{Orientation: LeftToRight})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nbDiff, _ := visualDiff(expected, Render(leftToRight, nil)); nbDiff > 0 {
+			t.Errorf("%s: %d pixels differ", file, nbDiff)
+		}
+	}
+}
+
+func TestRenderTimeSpacing(t *testing.T) {
+	inputNodes := []*Node{
+		{idKey: "a", parentsKey: []string{"b"}, timestampKey: 3 * 3600},
+		{idKey: "b", parentsKey: []string{}, timestampKey: 0},
+	}
+	out, err := LayoutContext(context.Background(), inputNodes, &Options{TimeSpacing: &TimeSpacing{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := Render(out, nil)
+	pad := 5
+	assertEq(t, 2*pad+3*renderYGap+1, img.Bounds().Dy())
+	assertEq(t, [3]uint8{0x00, 0x5e, 0xbe}, pixelAt(img, pad, pad+3*renderYGap))
+}
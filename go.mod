@@ -5,6 +5,7 @@ go 1.21
 require (
 	github.com/sirupsen/logrus v1.9.3
 	github.com/urfave/cli v1.22.14
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
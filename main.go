@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/alaingilbert/git2graph/git2graph"
+	"github.com/alaingilbert/git2graph/git2graph/gen"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -23,9 +29,60 @@ func startAction(c *cli.Context) error {
 	repoLinearFlag := c.Bool("repo-linear")
 	seqIds := c.Bool("seq-ids")
 	rowsFlag := c.Bool("rows")
+	cacheFlag := c.Bool("cache")
+	cacheIntervalFlag := c.Int("cache-interval")
+	maxNodesFlag := c.Int("max-nodes")
+	maxColumnsFlag := c.Int("max-columns")
+	maxPathPointsFlag := c.Int("max-path-points")
+	timeoutFlag := c.Duration("timeout")
+	maxLanesFlag := c.Int("max-lanes")
+	priorityRefsFlag := c.StringSlice("priority-ref")
+	branchColorsFlag := c.Bool("branch-colors")
+	colorByFlag := c.String("color-by")
+	colorMapFlag := c.StringSlice("color-map")
+	themeFlag := c.String("theme")
+	printThemeFlag := c.Bool("print-theme")
+	bottomUpFlag := c.Bool("bottom-up")
+	horizontalFlag := c.Bool("horizontal")
+	timeSpacingFlag := c.Duration("time-spacing")
+	minGapFlag := c.Float64("min-gap")
+	idleGapFlag := c.Float64("idle-gap")
+	foldRunsFlag := c.Int("fold-runs")
+	foldMergedFlag := c.Bool("fold-merged")
+	lanesFlag := c.String("lanes")
+	lanesReportFlag := c.Bool("lanes-report")
+	metricsReportFlag := c.Bool("metrics-report")
+	pngFlag := c.String("png")
+	uncommittedFlag := c.Bool("uncommitted")
+	stashesFlag := c.Bool("stashes")
 	logLevel := c.String("log")
 	setLogLevel(logLevel)
 
+	if bottomUpFlag && horizontalFlag {
+		err = errors.New("--bottom-up and --horizontal cannot be used together")
+		log.Error(err)
+		return err
+	}
+	theme, err := git2graph.GetTheme(themeFlag)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if printThemeFlag {
+		return json.NewEncoder(os.Stdout).Encode(theme)
+	}
+	if lanesReportFlag {
+		return git2graph.WriteLanesReport(os.Stdout, c.Args())
+	}
+	laneAssigner, err := git2graph.GetLaneAssigner(lanesFlag)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if metricsReportFlag {
+		return git2graph.WriteMetricsReport(os.Stdout, c.Args(), &git2graph.Options{LaneAssigner: laneAssigner})
+	}
+
 	if repoFlag || repoLinearFlag {
 		order := git2graph.DefaultOrder
 		if topoOrderFlag {
@@ -33,10 +90,16 @@ func startAction(c *cli.Context) error {
 		} else if dateOrderFlag {
 			order = git2graph.DateOrder
 		}
-		if seqIds {
-			nodes, err = git2graph.GetInputNodesFromRepoSeq("", order, limitFlag)
-		} else {
-			nodes, err = git2graph.GetInputNodesFromRepo("", order, limitFlag)
+		nodes, err = git2graph.GetInputNodesFromRepo("", order, limitFlag)
+		if err == nil && stashesFlag {
+			nodes, err = git2graph.IncludeStashes("", nodes)
+		}
+		// The uncommitted changes node is added with the shas, its parent can be a HEAD sha that was not loaded
+		if err == nil && uncommittedFlag {
+			nodes, err = git2graph.PrependUncommitted("", nodes)
+		}
+		if err == nil && seqIds {
+			nodes = git2graph.SequentialIDs(nodes)
 		}
 		if repoLinearFlag {
 			git2graph.SerializeOutput(&git2graph.Out{Nodes: nodes})
@@ -54,11 +117,62 @@ func startAction(c *cli.Context) error {
 		return err
 	}
 
+	opts := &git2graph.Options{
+		From:               fromFlag,
+		Limit:              limitFlag,
+		MaxNodes:           maxNodesFlag,
+		MaxColumns:         maxColumnsFlag,
+		MaxPathPoints:      maxPathPointsFlag,
+		MaxLanes:           maxLanesFlag,
+		PriorityRefs:       priorityRefsFlag,
+		FoldLinearRuns:     foldRunsFlag,
+		FoldMergedBranches: foldMergedFlag,
+		LaneAssigner:       laneAssigner,
+	}
+	if bottomUpFlag {
+		opts.Orientation = git2graph.BottomUp
+	}
+	if horizontalFlag {
+		opts.Orientation = git2graph.LeftToRight
+	}
+	if timeSpacingFlag > 0 {
+		opts.TimeSpacing = &git2graph.TimeSpacing{Unit: timeSpacingFlag, MinGap: minGapFlag, IdleGap: idleGapFlag}
+	}
+	opts.ColorGen = git2graph.NewCycleColorGen(theme.Palette)
+	if branchColorsFlag {
+		opts.ColorGen = git2graph.NewBranchColorGen(theme.Palette)
+	}
+	if colorByFlag != "" {
+		valuesMap := make(map[string]string)
+		for _, entry := range colorMapFlag {
+			value, clr, ok := strings.Cut(entry, "=")
+			if !ok {
+				err = fmt.Errorf("invalid color map entry %q, expected value=color", entry)
+				log.Error(err)
+				return err
+			}
+			valuesMap[value] = clr
+		}
+		opts.ColorGen = git2graph.NewAttributeColorGen(colorByFlag, theme.Palette, valuesMap)
+	}
+	if cacheFlag {
+		if opts.Cache, err = openLayoutCache(cacheIntervalFlag); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+	ctx := context.Background()
+	if timeoutFlag > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeoutFlag)
+		defer cancel()
+	}
+
 	var out *git2graph.Out
 	if rowsFlag {
-		out, err = git2graph.GetPaginatedRows(nodes, fromFlag, limitFlag)
+		out, err = git2graph.LayoutRowsContext(ctx, nodes, opts)
 	} else {
-		out, err = git2graph.GetPaginated(nodes, fromFlag, limitFlag)
+		out, err = git2graph.LayoutContext(ctx, nodes, opts)
 	}
 	if err != nil {
 		log.Error(err)
@@ -67,9 +181,130 @@ func startAction(c *cli.Context) error {
 
 	git2graph.SerializeOutput(out)
 
+	if pngFlag != "" {
+		if rowsFlag {
+			err = errors.New("--png renders the tree output, not the rows one")
+			log.Error(err)
+			return err
+		}
+		if err = writePNG(pngFlag, out, theme); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
 	return err
 }
 
+func genAction(c *cli.Context) error {
+	cfg := gen.Config{
+		Seed:           c.Int64("seed"),
+		Commits:        c.Int("commits"),
+		Branches:       c.Int("branches"),
+		FeatureRate:    c.Float64("feature-rate"),
+		FeatureLength:  c.Int("feature-length"),
+		MergeBackRate:  c.Float64("merge-back-rate"),
+		OctopusRate:    c.Float64("octopus-rate"),
+		CherryPickRate: c.Float64("cherry-pick-rate"),
+		Orphans:        c.Int("orphans"),
+	}
+	return json.NewEncoder(os.Stdout).Encode(gen.Generate(cfg))
+}
+
+func exportFixtureAction(c *cli.Context) error {
+	var nodes []*git2graph.Node
+	var err error
+	fromFlag := c.String("from")
+	limitFlag := c.Int("limit")
+	if c.Bool("repo") {
+		order := git2graph.DefaultOrder
+		if c.Bool("topo-order") {
+			order = git2graph.TopoOrder
+		} else if c.Bool("date-order") {
+			order = git2graph.DateOrder
+		}
+		// Shas are replaced by ExportFixture, --from is one of them.
+		// The rows above --from are part of the fixture, the whole history is needed to find them.
+		limit := limitFlag
+		if fromFlag != "" {
+			limit = -1
+		}
+		nodes, err = git2graph.GetInputNodesFromRepo("", order, limit)
+	} else if c.String("json") != "" {
+		nodes, err = git2graph.GetInputNodesFromJSON([]byte(c.String("json")))
+	} else if c.String("file") != "" {
+		nodes, err = git2graph.GetInputNodesFromFile(c.String("file"))
+	} else {
+		return cli.ShowCommandHelp(c, c.Command.Name)
+	}
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	fixture, fixtureFrom, err := git2graph.ExportFixture(nodes, fromFlag, limitFlag)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	path := c.String("output")
+	if path == "" {
+		path = nextFixturePath()
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if err := git2graph.WriteFixture(f, fixture); err != nil {
+		f.Close()
+		log.Error(err)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		log.Error(err)
+		return err
+	}
+	args := "-f " + path
+	if fixtureFrom != "" {
+		args += " --from " + fixtureFrom
+	}
+	if limitFlag > 0 {
+		args += fmt.Sprintf(" --limit %d", limitFlag)
+	}
+	fmt.Printf("Wrote %s, reproduce the layout with: git2graph %s\n", path, args)
+	return nil
+}
+
+// Return the first test_NNN.json file name that does not exist in the current directory
+func nextFixturePath() string {
+	for i := 1; ; i++ {
+		path := fmt.Sprintf("test_%03d.json", i)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
+
+func writePNG(path string, out *git2graph.Out, theme *git2graph.Theme) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := git2graph.RenderPNG(f, out, theme); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func openLayoutCache(interval int) (*git2graph.LayoutCache, error) {
+	cachePath, err := git2graph.RepoCachePath("")
+	if err != nil {
+		return nil, err
+	}
+	return git2graph.NewLayoutCache(cachePath, interval)
+}
+
 func setLogLevel(logLevel string) {
 	switch logLevel {
 	case "debug":
@@ -109,6 +344,8 @@ func main() {
 		cli.StringFlag{Name: "L, log", Usage: "Log level"},
 		cli.BoolFlag{Name: "r, repo", Usage: "Repository"},
 		cli.BoolFlag{Name: "topo-order", Usage: "Topological order"},
+		cli.BoolFlag{Name: "uncommitted", Usage: "Add an uncommitted changes node above HEAD when the working tree or index is dirty"},
+		cli.BoolFlag{Name: "stashes", Usage: "Show the stashes as single nodes above the commit they were created on"},
 		cli.BoolFlag{Name: "l, repo-linear", Usage: "Repository linear history"},
 		cli.BoolFlag{Name: "s, seq-ids", Usage: "Use sequential ids instead of sha for linear history"},
 		cli.BoolFlag{Name: "n, no-output", Usage: "No output"},
@@ -116,8 +353,65 @@ func main() {
 		cli.StringFlag{Name: "from", Usage: "From"},
 		cli.IntFlag{Name: "limit", Usage: "Limit", Value: -1},
 		cli.BoolFlag{Name: "context", Usage: "Include context"},
+		cli.BoolFlag{Name: "cache", Usage: "Cache layout snapshots in .git/git2graph"},
+		cli.IntFlag{Name: "cache-interval", Usage: "Number of rows in between two cached snapshots", Value: git2graph.DefaultCacheInterval},
+		cli.IntFlag{Name: "max-nodes", Usage: "Maximum number of input nodes"},
+		cli.IntFlag{Name: "max-columns", Usage: "Maximum number of columns"},
+		cli.IntFlag{Name: "max-path-points", Usage: "Maximum number of path points laid out"},
+		cli.DurationFlag{Name: "timeout", Usage: "Maximum layout duration"},
+		cli.IntFlag{Name: "max-lanes", Usage: "Maximum number of visible columns, the others are collapsed in an overflow lane"},
+		cli.StringSliceFlag{Name: "priority-ref", Usage: "Ref pattern pinned to the leftmost columns (repeatable, in order)"},
+		cli.BoolFlag{Name: "branch-colors", Usage: "Color lanes by branch name, stable across pages"},
+		cli.StringFlag{Name: "color-by", Usage: "Color nodes and their outgoing paths by a node attribute (email, name...)"},
+		cli.StringSliceFlag{Name: "color-map", Usage: "Color of an attribute value for --color-by, value=color (repeatable)"},
+		cli.StringFlag{Name: "theme", Usage: "Built-in theme (" + strings.Join(git2graph.ThemeNames(), ", ") + ") or json/yaml theme file", Value: git2graph.LightTheme.Name},
+		cli.BoolFlag{Name: "print-theme", Usage: "Print the theme settings for the renderers"},
+		cli.BoolFlag{Name: "bottom-up", Usage: "Oldest commits at the top"},
+		cli.BoolFlag{Name: "horizontal", Usage: "Timeline from left to right, oldest commits on the left"},
+		cli.DurationFlag{Name: "time-spacing", Usage: "Space the commits by elapsed time, duration for a distance of 1 (1h, 24h...)"},
+		cli.Float64Flag{Name: "min-gap", Usage: "Minimum distance in between two commits with --time-spacing", Value: 1},
+		cli.Float64Flag{Name: "idle-gap", Usage: "Distances above idle-gap are compressed with --time-spacing, 0 for no compression"},
+		cli.IntFlag{Name: "fold-runs", Usage: "Fold the runs of at least N linear commits into one placeholder node"},
+		cli.BoolFlag{Name: "fold-merged", Usage: "Fold each merged branch into one node attached to its merge"},
+		cli.StringFlag{Name: "lanes", Usage: "Lane assigner, greedy or compact", Value: git2graph.GreedyLanes.Name()},
+		cli.BoolFlag{Name: "lanes-report", Usage: "Print the columns and crossings of every lane assigner for the json files given as arguments"},
+		cli.BoolFlag{Name: "metrics-report", Usage: "Print the layout quality metrics of the json files given as arguments"},
+		cli.StringFlag{Name: "png", Usage: "Render the tree output with the theme into a png file"},
 	}
 	app.Action = startAction
+	app.Commands = []cli.Command{
+		{
+			Name:   "gen",
+			Usage:  "Generate a synthetic history in the json input format",
+			Action: genAction,
+			Flags: []cli.Flag{
+				cli.Int64Flag{Name: "seed", Usage: "Random seed, the same seed and flags give the same history", Value: gen.DefaultConfig.Seed},
+				cli.IntFlag{Name: "commits", Usage: "Number of commits", Value: gen.DefaultConfig.Commits},
+				cli.IntFlag{Name: "branches", Usage: "Number of long-lived branches besides main", Value: gen.DefaultConfig.Branches},
+				cli.Float64Flag{Name: "feature-rate", Usage: "Probability of starting a feature branch at each commit", Value: gen.DefaultConfig.FeatureRate},
+				cli.IntFlag{Name: "feature-length", Usage: "Average number of commits of a feature branch", Value: gen.DefaultConfig.FeatureLength},
+				cli.Float64Flag{Name: "merge-back-rate", Usage: "Probability of merging a long-lived branch into main, or main into it, at each commit", Value: gen.DefaultConfig.MergeBackRate},
+				cli.Float64Flag{Name: "octopus-rate", Usage: "Probability of merging the finished feature branches at once at each commit", Value: gen.DefaultConfig.OctopusRate},
+				cli.Float64Flag{Name: "cherry-pick-rate", Usage: "Probability of copying commits of a feature branch onto another branch at each commit", Value: gen.DefaultConfig.CherryPickRate},
+				cli.IntFlag{Name: "orphans", Usage: "Number of additional root commits", Value: gen.DefaultConfig.Orphans},
+			},
+		},
+		{
+			Name:   "export-fixture",
+			Usage:  "Write the anonymized subgraph needed to reproduce the layout of a page, for bug reports",
+			Action: exportFixtureAction,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "f, file", Usage: "File"},
+				cli.StringFlag{Name: "j, json", Usage: "Json input"},
+				cli.BoolFlag{Name: "r, repo", Usage: "Repository"},
+				cli.BoolFlag{Name: "topo-order", Usage: "Topological order"},
+				cli.BoolFlag{Name: "date-order", Usage: "Date order"},
+				cli.StringFlag{Name: "from", Usage: "From"},
+				cli.IntFlag{Name: "limit", Usage: "Limit", Value: -1},
+				cli.StringFlag{Name: "o, output", Usage: "Fixture file, defaults to the first test_NNN.json that does not exist"},
+			},
+		},
+	}
 	if err := app.Run(os.Args); err != nil {
 		log.Fatal(err)
 	}